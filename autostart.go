@@ -0,0 +1,118 @@
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// shouldAutoStart 判断下一阶段是否按设置自动开始
+func shouldAutoStart() bool {
+	if nextState == stateBreaking {
+		return setting.Timer.AutoStartBreak
+	}
+	return setting.Timer.AutoStartWork
+}
+
+// showAutoStartCountdown 倒数几秒后自动开始下一阶段，期间可以立即开始或取消
+func showAutoStartCountdown(title, message string) {
+	seconds := setting.Timer.AutoStartDelay
+	if seconds <= 0 {
+		startTimer()
+		return
+	}
+
+	done := false
+	countdownLabel := widget.NewLabel(autoStartText(seconds))
+	autoDialog := dialog.NewCustomConfirm(
+		title,
+		tr("autoStart.now"),
+		tr("button.cancel"),
+		container.NewVBox(
+			container.NewCenter(canvas.NewText(message, theme.TextColor())),
+			container.NewCenter(countdownLabel),
+		),
+		func(start bool) {
+			if done {
+				return
+			}
+			done = true
+			// 倒计时期间可能已经从托盘、热键或迷你窗口开始了
+			if start && currentState == stateIdle {
+				startTimer()
+			}
+		},
+		window,
+	)
+	autoDialog.Resize(fyne.NewSize(240, 170))
+	autoDialog.Show()
+
+	go func() {
+		for left := seconds - 1; left >= 0; left-- {
+			time.Sleep(time.Second)
+			finished := left == 0
+			text := autoStartText(left)
+			stop := false
+			fyne.DoAndWait(func() {
+				if done {
+					stop = true
+					return
+				}
+				if currentState != stateIdle {
+					// 已经从别处开始，不再自动开始
+					done, stop = true, true
+					autoDialog.Hide()
+					return
+				}
+				if !finished {
+					countdownLabel.SetText(text)
+					return
+				}
+				done = true
+				autoDialog.Hide()
+				startTimer()
+			})
+			if stop {
+				return
+			}
+		}
+	}()
+}
+
+func autoStartText(seconds int) string {
+	return trn("autoStart.countdown", seconds, seconds)
+}
+
+// withinWorkingHours 判断当前时间是否在设置的工作时间内，未设置时视为全天
+func withinWorkingHours(now time.Time) bool {
+	start, errStart := time.Parse("15:04", setting.Timer.WorkHoursStart)
+	end, errEnd := time.Parse("15:04", setting.Timer.WorkHoursEnd)
+	if errStart != nil || errEnd != nil {
+		return true
+	}
+	minutes := now.Hour()*60 + now.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+	if startMinutes <= endMinutes {
+		return minutes >= startMinutes && minutes < endMinutes
+	}
+	// 跨午夜的工作时间，如 22:00-06:00
+	return minutes >= startMinutes || minutes < endMinutes
+}
+
+// autoStartOnLaunch 启动后在工作时间内自动开始专注
+func autoStartOnLaunch() {
+	if !setting.Timer.AutoStartOnLaunch || timerMode() != modePomodoro || currentState != stateIdle {
+		return
+	}
+	if !withinWorkingHours(time.Now()) {
+		return
+	}
+	logInfo("auto start on launch")
+	startTimer()
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"golang.org/x/image/draw"
+)
+
+// 背景图片显示方式
+const (
+	bgModeFill = "fill"
+	bgModeFit  = "fit"
+	bgModeTile = "tile"
+)
+
+var bgModeOrder = []string{bgModeFill, bgModeFit, bgModeTile}
+
+// 模糊时先缩小到原图的几分之一，再平滑放大
+const bgBlurFactor = 12
+
+var (
+	bgImage *backgroundImage
+	// bgDim 盖在背景图片上的半透明底色，让倒计时文字保持清晰
+	bgDim *canvas.Rectangle
+	// bgImageCache 已解码的图片，key 为路径，模糊后的加 "#blur"
+	bgImageCache = make(map[string]image.Image)
+)
+
+// backgroundImage 按填充、适应或平铺方式绘制背景图片
+type backgroundImage struct {
+	widget.BaseWidget
+	img  image.Image
+	mode string
+}
+
+func newBackgroundImage() *backgroundImage {
+	b := &backgroundImage{}
+	b.ExtendBaseWidget(b)
+	return b
+}
+
+func (b *backgroundImage) SetImage(img image.Image, mode string) {
+	b.img = img
+	b.mode = mode
+	b.Refresh()
+}
+
+func (b *backgroundImage) CreateRenderer() fyne.WidgetRenderer {
+	return &backgroundImageRenderer{bg: b}
+}
+
+type backgroundImageRenderer struct {
+	bg      *backgroundImage
+	img     image.Image
+	objects []fyne.CanvasObject
+}
+
+func (r *backgroundImageRenderer) Layout(size fyne.Size) {
+	img := r.bg.img
+	if img == nil || size.Width <= 0 || size.Height <= 0 {
+		r.objects = nil
+		return
+	}
+	bounds := img.Bounds()
+	imgW, imgH := float32(bounds.Dx()), float32(bounds.Dy())
+	switch r.bg.mode {
+	case bgModeTile:
+		cols := int(math.Ceil(float64(size.Width / imgW)))
+		rows := int(math.Ceil(float64(size.Height / imgH)))
+		r.ensureTiles(img, cols*rows)
+		for i, obj := range r.objects {
+			obj.Resize(fyne.NewSize(imgW, imgH))
+			obj.Move(fyne.NewPos(float32(i%cols)*imgW, float32(i/cols)*imgH))
+		}
+	case bgModeFit:
+		r.ensureTiles(img, 1)
+		r.objects[0].(*canvas.Image).FillMode = canvas.ImageFillContain
+		r.objects[0].Resize(size)
+		r.objects[0].Move(fyne.NewPos(0, 0))
+	default:
+		// 按比例放大到铺满窗口，超出部分在窗口外
+		scale := float32(math.Max(float64(size.Width/imgW), float64(size.Height/imgH)))
+		fill := fyne.NewSize(imgW*scale, imgH*scale)
+		r.ensureTiles(img, 1)
+		r.objects[0].(*canvas.Image).FillMode = canvas.ImageFillStretch
+		r.objects[0].Resize(fill)
+		r.objects[0].Move(fyne.NewPos((size.Width-fill.Width)/2, (size.Height-fill.Height)/2))
+	}
+}
+
+// ensureTiles 准备指定数量的图片对象，图片变化时重新创建
+func (r *backgroundImageRenderer) ensureTiles(img image.Image, count int) {
+	if r.img != img {
+		r.objects = nil
+		r.img = img
+	}
+	for len(r.objects) < count {
+		tile := canvas.NewImageFromImage(img)
+		tile.ScaleMode = canvas.ImageScaleSmooth
+		r.objects = append(r.objects, tile)
+	}
+	r.objects = r.objects[:count]
+}
+
+func (r *backgroundImageRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(0, 0)
+}
+
+func (r *backgroundImageRenderer) Refresh() {
+	r.Layout(r.bg.Size())
+	for _, obj := range r.objects {
+		obj.Refresh()
+	}
+}
+
+func (r *backgroundImageRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *backgroundImageRenderer) Destroy() {}
+
+// createBackground 返回放在主界面底色之上、控件之下的背景层
+func createBackground() fyne.CanvasObject {
+	bgImage = newBackgroundImage()
+	bgDim = canvas.NewRectangle(color.Transparent)
+	updateBackground()
+	return container.NewStack(bgImage, bgDim)
+}
+
+// backgroundPath 休息时优先使用休息背景
+func backgroundPath() string {
+	if (currentState == stateBreaking || (currentState == stateIdle && nextState == stateBreaking)) && setting.Appearance.BgBreakImagePath != "" {
+		return setting.Appearance.BgBreakImagePath
+	}
+	return setting.Appearance.BgImagePath
+}
+
+// updateBackground 根据状态和设置刷新背景图片和遮罩
+func updateBackground() {
+	if bgImage == nil {
+		return
+	}
+	path := backgroundPath()
+	var img image.Image
+	if path != "" {
+		var err error
+		// 平铺时按原图大小显示，不做模糊
+		blur := setting.Appearance.BgImageBlur && setting.Appearance.BgImageMode != bgModeTile
+		if img, err = loadBackgroundImage(path, blur); err != nil {
+			logError("load background image error", err)
+		}
+	}
+	bgImage.SetImage(img, setting.Appearance.BgImageMode)
+
+	if img == nil {
+		bgDim.FillColor = color.Transparent
+	} else {
+		dim := color.NRGBAModel.Convert(bgColor).(color.NRGBA)
+		dim.A = uint8(math.Round(setting.Appearance.BgImageDim * 255))
+		bgDim.FillColor = dim
+	}
+	bgDim.Refresh()
+}
+
+func loadBackgroundImage(path string, blur bool) (image.Image, error) {
+	key := path
+	if blur {
+		key += "#blur"
+	}
+	if img, ok := bgImageCache[key]; ok {
+		return img, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	if blur {
+		img = blurImage(img)
+	}
+	bgImageCache[key] = img
+	return img, nil
+}
+
+// blurImage 缩小后由显示时的平滑放大产生模糊效果
+func blurImage(img image.Image) image.Image {
+	bounds := img.Bounds()
+	w := maxInt(bounds.Dx()/bgBlurFactor, 1)
+	h := maxInt(bounds.Dy()/bgBlurFactor, 1)
+	small := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), img, bounds, draw.Src, nil)
+	return small
+}
+
+// selectBackgroundImage 选择图片前先确认能解码
+func selectBackgroundImage(onSelected func(string)) {
+	selectFile(func(filePath string) {
+		if _, err := loadBackgroundImage(filePath, false); err != nil {
+			logError("decode background image error", err)
+			dialog.ShowInformation(tr("dialog.hint"), tr("bg.decodeFailed"), settingsWindow)
+			return
+		}
+		onSelected(filePath)
+		updateBackground()
+	}, "img")
+}
+
+func bgModeLabel(mode string) string {
+	return tr("bg.mode." + mode)
+}
+
+// createBackgroundSettings 设置窗口中的背景图片、休息背景和显示方式
+func createBackgroundSettings() []*widget.FormItem {
+	pathRow := func(path *string, pathLabel *widget.Label, emptyText string) fyne.CanvasObject {
+		pathLabel.SetText(emptyText)
+		if *path != "" {
+			pathLabel.SetText(truncatePath(*path, 30))
+		}
+		changeBtn := widget.NewButton(tr("button.change"), func() {
+			selectBackgroundImage(func(filePath string) {
+				*path = filePath
+				pathLabel.SetText(truncatePath(filePath, 30))
+			})
+		})
+		clearBtn := widget.NewButton(tr("bg.clear"), func() {
+			*path = ""
+			pathLabel.SetText(emptyText)
+			updateBackground()
+		})
+		return container.NewHBox(pathLabel, layout.NewSpacer(), changeBtn, clearBtn)
+	}
+
+	labels := make([]string, 0, len(bgModeOrder))
+	for _, mode := range bgModeOrder {
+		labels = append(labels, bgModeLabel(mode))
+	}
+	modeSelect := widget.NewSelect(labels, func(selected string) {
+		for _, mode := range bgModeOrder {
+			if bgModeLabel(mode) == selected && mode != setting.Appearance.BgImageMode {
+				setting.Appearance.BgImageMode = mode
+				updateBackground()
+			}
+		}
+	})
+	modeSelect.SetSelected(bgModeLabel(setting.Appearance.BgImageMode))
+
+	dimSlider := widget.NewSlider(0, 0.9)
+	dimSlider.Step = 0.05
+	dimSlider.SetValue(setting.Appearance.BgImageDim)
+	dimSlider.OnChangeEnded = func(value float64) {
+		setting.Appearance.BgImageDim = value
+		updateBackground()
+	}
+
+	blurCheck := widget.NewCheck(tr("bg.blur"), func(checked bool) {
+		setting.Appearance.BgImageBlur = checked
+		updateBackground()
+	})
+	blurCheck.SetChecked(setting.Appearance.BgImageBlur)
+
+	dimRow := container.NewBorder(nil, nil, widget.NewLabel(tr("bg.dim")), blurCheck, dimSlider)
+	setting.bgPathText = widget.NewLabel("")
+	breakPathText := widget.NewLabel("")
+	return []*widget.FormItem{
+		widget.NewFormItem(tr("settings.bgImage"), pathRow(&setting.Appearance.BgImagePath, setting.bgPathText, tr("settings.notSet"))),
+		widget.NewFormItem(tr("settings.bgBreakImage"), pathRow(&setting.Appearance.BgBreakImagePath, breakPathText, tr("bg.sameAsWork"))),
+		widget.NewFormItem(tr("settings.bgDisplay"), container.NewVBox(modeSelect, dimRow)),
+	}
+}
@@ -0,0 +1,25 @@
+//go:build linux
+
+package main
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// awakeClock 返回不含休眠时间的时钟读数，计时以它为准。Go 的单调时钟在 Linux 上
+// 用 CLOCK_MONOTONIC，休眠时不走
+func awakeClock() time.Duration {
+	return time.Since(clockStart)
+}
+
+// suspendClock 返回包含休眠时间的时钟读数，Linux 上用 CLOCK_BOOTTIME，
+// 它与单调时钟的差值就是休眠时长，调整系统时间不会影响它
+func suspendClock() time.Duration {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_BOOTTIME, &ts); err != nil {
+		return time.Duration(time.Now().UnixNano())
+	}
+	return time.Duration(ts.Nano())
+}
@@ -0,0 +1,17 @@
+//go:build !linux && !windows
+
+package main
+
+import "time"
+
+// awakeClock 返回不含休眠时间的时钟读数，计时以它为准。Go 的单调时钟在 macOS 上
+// 用 mach_absolute_time，休眠时不走
+func awakeClock() time.Duration {
+	return time.Since(clockStart)
+}
+
+// suspendClock 返回包含休眠时间的时钟读数，其他平台只能用墙上时间，
+// 手动调整系统时间也会被当作休眠
+func suspendClock() time.Duration {
+	return time.Duration(time.Now().UnixNano())
+}
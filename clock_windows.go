@@ -0,0 +1,29 @@
+//go:build windows
+
+package main
+
+import (
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32                       = windows.NewLazySystemDLL("kernel32.dll")
+	procQueryUnbiasedInterruptTime = kernel32.NewProc("QueryUnbiasedInterruptTime")
+)
+
+// awakeClock 返回不含休眠时间的时钟读数，计时以它为准。Windows 上 Go 的单调时钟读的是
+// 中断时间，休眠时照样走，这里改用不含休眠的 QueryUnbiasedInterruptTime，单位为 100 纳秒
+func awakeClock() time.Duration {
+	var ticks uint64
+	procQueryUnbiasedInterruptTime.Call(uintptr(unsafe.Pointer(&ticks)))
+	return time.Duration(ticks) * 100
+}
+
+// suspendClock 返回包含休眠时间的时钟读数，Windows 上 Go 的单调时钟包含休眠，
+// 调整系统时间也不会影响它
+func suspendClock() time.Duration {
+	return time.Since(clockStart)
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// 最近使用的颜色最多保留几个
+const maxRecentColors = 8
+
+// colorSwatch 色块，点击时回调
+type colorSwatch struct {
+	widget.BaseWidget
+	rect     *canvas.Rectangle
+	onTapped func()
+}
+
+func newColorSwatch(c color.Color, size fyne.Size, onTapped func()) *colorSwatch {
+	rect := canvas.NewRectangle(c)
+	rect.SetMinSize(size)
+	rect.StrokeColor = color.NRGBA{R: 128, G: 128, B: 128, A: 160}
+	rect.StrokeWidth = 1
+	rect.CornerRadius = 4
+	s := &colorSwatch{rect: rect, onTapped: onTapped}
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+func (s *colorSwatch) SetColor(c color.Color) {
+	s.rect.FillColor = c
+	s.rect.Refresh()
+}
+
+func (s *colorSwatch) Tapped(*fyne.PointEvent) {
+	if s.onTapped != nil {
+		s.onTapped()
+	}
+}
+
+func (s *colorSwatch) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(s.rect)
+}
+
+// colorField 设置窗口中的一项颜色：色块预览、十六进制输入和取色按钮，
+// 颜色仍以 hexToColor/colorToHex 的格式保存
+type colorField struct {
+	title     string
+	swatch    *colorSwatch
+	entryBox  *fyne.Container
+	entry     *widget.Entry
+	onChanged func(c color.Color, hex string)
+	// fallback 允许留空时的默认颜色
+	fallback func() color.Color
+}
+
+func newColorField(title, hex string, onChanged func(c color.Color, hex string)) *colorField {
+	f := &colorField{title: title, onChanged: onChanged}
+	current, err := hexToColor(hex)
+	if err != nil {
+		current = color.Transparent
+	}
+	f.swatch = newColorSwatch(current, fyne.NewSize(36, 24), f.showPicker)
+
+	f.entryBox = newFixedWidthEntry(110, 36)
+	f.entry = f.entryBox.Objects[0].(*widget.Entry)
+	f.entry.SetText(hex)
+	f.entry.Validator = validateHexColor
+	f.entry.OnChanged = func(text string) {
+		c, err := hexToColor(text)
+		if err != nil {
+			return
+		}
+		f.swatch.SetColor(c)
+		f.onChanged(c, colorToHex(c))
+	}
+	return f
+}
+
+// Object 返回用于放进表单的控件
+func (f *colorField) Object() fyne.CanvasObject {
+	pickBtn := widget.NewButtonWithIcon("", theme.ColorPaletteIcon(), f.showPicker)
+	return container.NewHBox(container.NewCenter(f.swatch), f.entryBox, pickBtn)
+}
+
+// AllowEmpty 允许清空输入框表示使用默认颜色，清空时色块显示 fallback 返回的颜色，回调中 hex 为空
+func (f *colorField) AllowEmpty(placeholder string, fallback func() color.Color) {
+	f.entry.SetPlaceHolder(placeholder)
+	f.entry.Validator = func(text string) error {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		return validateHexColor(text)
+	}
+	onText := f.entry.OnChanged
+	f.entry.OnChanged = func(text string) {
+		if strings.TrimSpace(text) != "" {
+			onText(text)
+			return
+		}
+		f.swatch.SetColor(fallback())
+		f.onChanged(fallback(), "")
+	}
+	f.fallback = fallback
+	f.ShowDefault()
+}
+
+// ShowDefault 输入框为空时按当前的默认颜色刷新色块
+func (f *colorField) ShowDefault() {
+	if f.fallback != nil && strings.TrimSpace(f.entry.Text) == "" {
+		f.swatch.SetColor(f.fallback())
+	}
+}
+
+// SetHex 修改颜色，和手动输入一样会触发回调
+func (f *colorField) SetHex(hex string) {
+	f.entry.SetText(hex)
+}
+
+func (f *colorField) showPicker() {
+	initial, err := hexToColor(f.entry.Text)
+	if err != nil {
+		initial = color.Black
+	}
+	showColorPicker(f.title, initial, func(c color.Color) {
+		f.SetHex(colorToHex(c))
+	})
+}
+
+func validateHexColor(text string) error {
+	if _, err := hexToColor(text); err != nil {
+		return errors.New(tr("color.invalid"))
+	}
+	return nil
+}
+
+// showColorPicker 取色对话框，可用 HSV 或 RGB 滑块调整，也可以直接输入或选最近用过的颜色
+func showColorPicker(title string, initial color.Color, onPicked func(color.Color)) {
+	current := color.NRGBAModel.Convert(initial).(color.NRGBA)
+	if current.A == 0 {
+		current.A = 255
+	}
+	// updating 为 true 时是程序在同步控件，不再反向触发
+	updating := false
+
+	preview := canvas.NewRectangle(current)
+	preview.SetMinSize(fyne.NewSize(240, 48))
+	preview.CornerRadius = 6
+
+	hexEntry := widget.NewEntry()
+	hexEntry.Validator = validateHexColor
+
+	sliders := make([]*widget.Slider, 3)
+	valueLabels := make([]*widget.Label, 3)
+	for i := range sliders {
+		sliders[i] = widget.NewSlider(0, 255)
+		valueLabels[i] = widget.NewLabel("")
+	}
+	nameLabels := []*widget.Label{widget.NewLabel(""), widget.NewLabel(""), widget.NewLabel("")}
+	useHSV := true
+
+	syncControls := func(skipHex bool) {
+		updating = true
+		defer func() { updating = false }()
+		preview.FillColor = current
+		preview.Refresh()
+		if !skipHex {
+			hexEntry.SetText(colorToHex(current))
+		}
+		var values [3]float64
+		if useHSV {
+			h, s, v := rgbToHSV(current)
+			values = [3]float64{h, s * 100, v * 100}
+		} else {
+			values = [3]float64{float64(current.R), float64(current.G), float64(current.B)}
+		}
+		for i, slider := range sliders {
+			slider.SetValue(values[i])
+			valueLabels[i].SetText(fmt.Sprintf("%.0f", values[i]))
+		}
+	}
+
+	applySliders := func() {
+		if updating {
+			return
+		}
+		if useHSV {
+			current = hsvToRGB(sliders[0].Value, sliders[1].Value/100, sliders[2].Value/100, current.A)
+		} else {
+			current = color.NRGBA{R: uint8(sliders[0].Value), G: uint8(sliders[1].Value), B: uint8(sliders[2].Value), A: current.A}
+		}
+		// 拖动滑块时不回写滑块，避免 HSV 换算误差让滑块跳动
+		updating = true
+		preview.FillColor = current
+		preview.Refresh()
+		hexEntry.SetText(colorToHex(current))
+		for i, slider := range sliders {
+			valueLabels[i].SetText(fmt.Sprintf("%.0f", slider.Value))
+		}
+		updating = false
+	}
+	for _, slider := range sliders {
+		slider.OnChanged = func(float64) { applySliders() }
+	}
+
+	setMode := func(hsv bool) {
+		useHSV = hsv
+		names := []string{"R", "G", "B"}
+		maxValues := []float64{255, 255, 255}
+		if hsv {
+			names = []string{"H", "S", "V"}
+			maxValues = []float64{359, 100, 100}
+		}
+		for i, slider := range sliders {
+			nameLabels[i].SetText(names[i])
+			slider.Max = maxValues[i]
+		}
+		syncControls(false)
+	}
+
+	hexEntry.OnChanged = func(text string) {
+		if updating {
+			return
+		}
+		c, err := hexToColor(text)
+		if err != nil {
+			return
+		}
+		current = color.NRGBAModel.Convert(c).(color.NRGBA)
+		syncControls(true)
+	}
+
+	modeRadio := widget.NewRadioGroup([]string{"HSV", "RGB"}, func(selected string) {
+		setMode(selected != "RGB")
+	})
+	modeRadio.Horizontal = true
+	modeRadio.SetSelected("HSV")
+
+	sliderRows := container.NewVBox()
+	for i := range sliders {
+		sliderRows.Add(container.NewBorder(nil, nil, nameLabels[i], valueLabels[i], sliders[i]))
+	}
+
+	recentRow := container.NewHBox()
+	for _, hex := range setting.Appearance.RecentColors {
+		c, err := hexToColor(hex)
+		if err != nil {
+			continue
+		}
+		recentRow.Add(newColorSwatch(c, fyne.NewSize(24, 24), func() {
+			current = color.NRGBAModel.Convert(c).(color.NRGBA)
+			syncControls(false)
+		}))
+	}
+
+	content := container.NewVBox(
+		preview,
+		modeRadio,
+		sliderRows,
+		widget.NewForm(widget.NewFormItem(tr("color.hex"), hexEntry)),
+	)
+	if len(recentRow.Objects) > 0 {
+		content.Add(widget.NewLabel(tr("color.recent")))
+		content.Add(recentRow)
+	}
+
+	parent := settingsWindow
+	if parent == nil {
+		parent = window
+	}
+	pickerDialog := dialog.NewCustomConfirm(title, tr("button.ok"), tr("button.cancel"), content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		addRecentColor(colorToHex(current))
+		onPicked(current)
+	}, parent)
+	pickerDialog.Resize(fyne.NewSize(360, 420))
+	pickerDialog.Show()
+}
+
+// addRecentColor 记录最近选择的颜色，最新的在最前
+func addRecentColor(hex string) {
+	recent := []string{hex}
+	for _, existing := range setting.Appearance.RecentColors {
+		if existing != hex && len(recent) < maxRecentColors {
+			recent = append(recent, existing)
+		}
+	}
+	setting.Appearance.RecentColors = recent
+}
+
+// rgbToHSV 返回色相 0-360，饱和度和明度 0-1
+func rgbToHSV(c color.NRGBA) (h, s, v float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	delta := maxC - minC
+	v = maxC
+	if maxC > 0 {
+		s = delta / maxC
+	}
+	if delta == 0 {
+		return 0, s, v
+	}
+	switch maxC {
+	case r:
+		h = math.Mod((g-b)/delta, 6)
+	case g:
+		h = (b-r)/delta + 2
+	default:
+		h = (r-g)/delta + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, v
+}
+
+func hsvToRGB(h, s, v float64, alpha uint8) color.NRGBA {
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return color.NRGBA{
+		R: uint8(math.Round((r + m) * 255)),
+		G: uint8(math.Round((g + m) * 255)),
+		B: uint8(math.Round((b + m) * 255)),
+		A: alpha,
+	}
+}
@@ -0,0 +1,175 @@
+//go:build linux && !wayland
+
+package main
+
+/*
+#cgo pkg-config: x11
+#include <X11/Xlib.h>
+#include <stdlib.h>
+
+// xtimerGrabError 抓取期间收到的 X 错误码，热键已被其他程序占用时为 BadAccess
+static int xtimerGrabError;
+
+static int xtimerGrabErrorHandler(Display *dpy, XErrorEvent *ev) {
+	xtimerGrabError = ev->error_code;
+	return 0;
+}
+
+// xtimerGrabKey 成功返回 1，按键名无效返回 0，热键已被占用等 X 错误返回 -1
+static int xtimerGrabKey(Display *dpy, const char *key, unsigned int mods) {
+	KeySym sym = XStringToKeysym(key);
+	if (sym == NoSymbol) {
+		return 0;
+	}
+	KeyCode code = XKeysymToKeycode(dpy, sym);
+	if (code == 0) {
+		return 0;
+	}
+	// 默认的错误处理会直接退出进程，抓取期间换成只记录错误码
+	xtimerGrabError = 0;
+	XErrorHandler previous = XSetErrorHandler(xtimerGrabErrorHandler);
+	// 同时抓取 CapsLock、NumLock 打开时的组合，否则这些锁定键会让热键失效
+	unsigned int extra[] = {0, LockMask, Mod2Mask, LockMask | Mod2Mask};
+	Window root = DefaultRootWindow(dpy);
+	for (int i = 0; i < 4; i++) {
+		XGrabKey(dpy, code, mods | extra[i], root, True, GrabModeAsync, GrabModeAsync);
+	}
+	XSync(dpy, False);
+	XSetErrorHandler(previous);
+	return xtimerGrabError == 0 ? 1 : -1;
+}
+
+// xtimerUngrabKeys 释放这个连接抓取的全部热键，等服务器处理完再返回
+static void xtimerUngrabKeys(Display *dpy) {
+	XUngrabKey(dpy, AnyKey, AnyModifier, DefaultRootWindow(dpy));
+	XSync(dpy, False);
+}
+
+static int xtimerHotkeyPressed(Display *dpy) {
+	int pressed = 0;
+	while (XPending(dpy) > 0) {
+		XEvent ev;
+		XNextEvent(dpy, &ev);
+		if (ev.type == KeyPress) {
+			pressed = 1;
+		}
+	}
+	return pressed;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+
+	"fyne.io/fyne/v2"
+)
+
+var (
+	hotkeyStop chan struct{}
+	// hotkeyDone 在轮询协程释放热键并关闭连接后关闭
+	hotkeyDone chan struct{}
+)
+
+// registerGlobalHotkey 在 X11 根窗口上抓取热键，按下时切换计时，binding 为空时只取消原有热键
+func registerGlobalHotkey(binding string) {
+	unregisterGlobalHotkey()
+	if strings.TrimSpace(binding) == "" {
+		return
+	}
+
+	key, mod, err := parseBinding(binding)
+	if err != nil {
+		logError("parse global hotkey error", err)
+		return
+	}
+
+	dpy := C.XOpenDisplay(nil)
+	if dpy == nil {
+		logError("open x11 display error", fmt.Errorf("global hotkey %s unavailable", binding))
+		return
+	}
+
+	keyName := C.CString(x11KeyName(key))
+	defer C.free(unsafe.Pointer(keyName))
+	switch C.xtimerGrabKey(dpy, keyName, x11Modifiers(mod)) {
+	case 0:
+		C.XCloseDisplay(dpy)
+		logError("grab global hotkey error", fmt.Errorf("unknown key: %s", key))
+		return
+	case -1:
+		C.XCloseDisplay(dpy)
+		logError("grab global hotkey error", fmt.Errorf("%s is already grabbed by another program", binding))
+		return
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	hotkeyStop, hotkeyDone = stop, done
+	go func() {
+		defer close(done)
+		defer C.XCloseDisplay(dpy)
+		pollTicker := time.NewTicker(100 * time.Millisecond)
+		defer pollTicker.Stop()
+		for {
+			select {
+			case <-stop:
+				C.xtimerUngrabKeys(dpy)
+				return
+			case <-pollTicker.C:
+				if C.xtimerHotkeyPressed(dpy) != 0 {
+					fyne.Do(toggleTimer)
+				}
+			}
+		}
+	}()
+}
+
+// unregisterGlobalHotkey 停止轮询并等待原有热键释放，之后重新抓取同一个键不会冲突
+func unregisterGlobalHotkey() {
+	if hotkeyStop == nil {
+		return
+	}
+	close(hotkeyStop)
+	<-hotkeyDone
+	hotkeyStop, hotkeyDone = nil, nil
+}
+
+func x11KeyName(key fyne.KeyName) string {
+	switch key {
+	case fyne.KeySpace:
+		return "space"
+	case fyne.KeyPlus:
+		return "plus"
+	case fyne.KeyMinus:
+		return "minus"
+	case fyne.KeyComma:
+		return "comma"
+	case fyne.KeyPeriod:
+		return "period"
+	}
+	if len(key) == 1 {
+		return strings.ToLower(string(key))
+	}
+	return string(key)
+}
+
+func x11Modifiers(mod fyne.KeyModifier) C.uint {
+	var mask C.uint
+	if mod&fyne.KeyModifierShift != 0 {
+		mask |= C.ShiftMask
+	}
+	if mod&fyne.KeyModifierControl != 0 {
+		mask |= C.ControlMask
+	}
+	if mod&fyne.KeyModifierAlt != 0 {
+		mask |= C.Mod1Mask
+	}
+	if mod&fyne.KeyModifierSuper != 0 {
+		mask |= C.Mod4Mask
+	}
+	return mask
+}
@@ -0,0 +1,6 @@
+//go:build !linux || wayland
+
+package main
+
+// registerGlobalHotkey 全局热键目前只支持 Linux/X11
+func registerGlobalHotkey(binding string) {}
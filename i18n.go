@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/jeandeaual/go-locale"
+)
+
+// 支持的界面语言，同时是 assets/i18n 下的文件名
+const (
+	langZhCN = "zh-CN"
+	langEn   = "en"
+)
+
+// defaultLang 找不到翻译时回退到的语言
+const defaultLang = langZhCN
+
+var langNames = map[string]string{
+	langZhCN: "简体中文",
+	langEn:   "English",
+}
+
+var langOrder = []string{langZhCN, langEn}
+
+var (
+	currentLang string
+	catalogs    = make(map[string]map[string]string)
+)
+
+// initI18n 加载翻译，设置中指定了语言时优先使用，否则按系统语言选择
+func initI18n() {
+	for _, lang := range langOrder {
+		data, err := assets.ReadFile("assets/i18n/" + lang + ".json")
+		if err != nil {
+			logError("read translation "+lang+" error", err)
+			continue
+		}
+		catalog := make(map[string]string)
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			logError("parse translation "+lang+" error", err)
+			continue
+		}
+		catalogs[lang] = catalog
+	}
+
+	currentLang = setting.Appearance.Language
+	if _, ok := catalogs[currentLang]; !ok {
+		currentLang = detectLang()
+	}
+	logInfo("ui language: %s", currentLang)
+}
+
+// detectLang 按系统语言列表选择第一个支持的语言
+func detectLang() string {
+	locales, err := locale.GetLocales()
+	if err != nil {
+		logError("get system locales error", err)
+		return defaultLang
+	}
+	for _, loc := range locales {
+		loc = strings.ToLower(loc)
+		switch {
+		case strings.HasPrefix(loc, "zh"):
+			return langZhCN
+		case strings.HasPrefix(loc, "en"):
+			return langEn
+		}
+	}
+	return langEn
+}
+
+// tr 返回 key 对应的当前语言文本，有参数时按 fmt 格式化
+func tr(key string, args ...interface{}) string {
+	text, ok := catalogs[currentLang][key]
+	if !ok {
+		if text, ok = catalogs[defaultLang][key]; !ok {
+			text = key
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// trn 按数量选择单复数形式，翻译中对应 key.one 和 key.other
+func trn(key string, count int, args ...interface{}) string {
+	return tr(key+"."+pluralForm(currentLang, count), args...)
+}
+
+// pluralForm 中文没有单复数之分，英文只有 1 用单数
+func pluralForm(lang string, count int) string {
+	if lang == langEn && count == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+// createLanguageSelect 设置窗口中的界面语言，重启后生效
+func createLanguageSelect() fyne.CanvasObject {
+	autoLabel := tr("settings.languageAuto")
+	options := []string{autoLabel}
+	for _, lang := range langOrder {
+		options = append(options, langNames[lang])
+	}
+	languageSelect := widget.NewSelect(options, func(selected string) {
+		setting.Appearance.Language = ""
+		for _, lang := range langOrder {
+			if langNames[lang] == selected {
+				setting.Appearance.Language = lang
+			}
+		}
+	})
+	if name, ok := langNames[setting.Appearance.Language]; ok {
+		languageSelect.SetSelected(name)
+	} else {
+		languageSelect.SetSelected(autoLabel)
+	}
+	return container.NewHBox(languageSelect, widget.NewLabel(tr("settings.restartHint")))
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// idleSource 提供用户没有输入的时长，不同平台各自实现，也方便替换成假的数据源
+type idleSource interface {
+	IdleTime() (time.Duration, error)
+}
+
+const idlePollInterval = 10 * time.Second
+
+var (
+	idleDetector idleSource
+	// idleUnavailable 当前系统无法检测空闲的原因，只在设置中提示，不改动用户的设置
+	idleUnavailable error
+	// idlePaused 因离开而自动暂停，等待用户回来
+	idlePaused bool
+	// idleSince 开始离开的时间，idleCounted 暂停前已计入专注的离开时长
+	idleSince   time.Time
+	idleCounted time.Duration
+)
+
+// startIdleMonitor 定时检查无操作时长，设置关闭时不查询
+func startIdleMonitor() {
+	go func() {
+		for range time.Tick(idlePollInterval) {
+			if !setting.Integrations.IdlePause {
+				continue
+			}
+			if idleDetector == nil {
+				source, err := newIdleSource()
+				if err != nil {
+					logError("idle detection unavailable", err)
+					fyne.Do(func() {
+						reportIdleUnavailable(err)
+					})
+					return
+				}
+				idleDetector = source
+			}
+			pollIdle(idleDetector)
+		}
+	}()
+}
+
+// pollIdle 查询一次无操作时长，交给界面线程检查是否需要暂停
+func pollIdle(source idleSource) {
+	idle, err := source.IdleTime()
+	if err != nil {
+		logError("query idle time error", err)
+		return
+	}
+	fyne.Do(func() {
+		checkIdle(idle)
+	})
+}
+
+// reportIdleUnavailable 提示无法检测空闲，离开暂停不会生效
+func reportIdleUnavailable(err error) {
+	idleUnavailable = err
+	myApp.SendNotification(fyne.NewNotification(tr("idle.title"), tr("idle.unavailable")))
+}
+
+// checkIdle 专注中无操作超过设定时间时暂停，回来后询问如何处理离开的时间
+func checkIdle(idle time.Duration) {
+	if idlePaused {
+		if currentState != statePause {
+			// 已手动继续或重置
+			idlePaused = false
+			return
+		}
+		if idle < idlePollInterval {
+			idlePaused = false
+			showIdleReturnDialog()
+		}
+		return
+	}
+
+	if currentState != stateWorking || !isRunning {
+		return
+	}
+	if idle < time.Duration(setting.Integrations.IdleMinutes)*time.Minute {
+		return
+	}
+	idleSince = time.Now().Add(-idle)
+	pauseTimer()
+	idleCounted = idle
+	if idleCounted > totalRunningTime {
+		idleCounted = totalRunningTime
+	}
+	idlePaused = true
+	logInfo("idle for %s, timer paused", idle.Round(time.Second))
+}
+
+// showIdleReturnDialog 回来后选择保留还是丢弃离开的时间，然后继续专注
+func showIdleReturnDialog() {
+	away := time.Since(idleSince)
+	message := tr("idle.message", formatDuration(away))
+	dialog.ShowCustomConfirm(tr("idle.title"), tr("idle.keep"), tr("idle.discard"), widget.NewLabel(message), func(keep bool) {
+		if currentState != statePause {
+			return
+		}
+		applyIdleReturn(keep, away)
+		startTimer()
+	}, window)
+}
+
+// applyIdleReturn 暂停前已计入了 idleCounted，保留时补上暂停后离开的时间，丢弃时扣掉已计入的部分
+func applyIdleReturn(keep bool, away time.Duration) {
+	if keep {
+		totalRunningTime += away - idleCounted
+	} else {
+		totalRunningTime -= idleCounted
+	}
+	remaining = total - totalRunningTime
+}
+
+// createIdleSettings 设置窗口中的离开检测
+func createIdleSettings() fyne.CanvasObject {
+	idleCheck := widget.NewCheck(tr("idle.check"), func(checked bool) {
+		setting.Integrations.IdlePause = checked
+	})
+	idleCheck.SetChecked(setting.Integrations.IdlePause)
+
+	minutesField := newIntField(tr("settings.idle"), 50, setting.Integrations.IdleMinutes, idleMinutesRange, func(val int) {
+		setting.Integrations.IdleMinutes = val
+	}, widget.NewLabel(tr("unit.minutes")))
+	row := container.NewHBox(idleCheck, minutesField)
+	if idleUnavailable == nil {
+		return row
+	}
+	hint := widget.NewLabel(tr("idle.unavailable"))
+	hint.Importance = widget.WarningImportance
+	return container.NewVBox(row, hint)
+}
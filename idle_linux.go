@@ -0,0 +1,43 @@
+//go:build linux
+
+package main
+
+import (
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// logindIdle 通过 logind 当前会话的 IdleHint 获取空闲状态，由桌面环境负责设置
+type logindIdle struct {
+	session dbus.BusObject
+}
+
+func newIdleSource() (idleSource, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+	session := conn.Object("org.freedesktop.login1", "/org/freedesktop/login1/session/auto")
+	return &logindIdle{session: session}, nil
+}
+
+func (l *logindIdle) IdleTime() (time.Duration, error) {
+	hint, err := l.session.GetProperty("org.freedesktop.login1.Session.IdleHint")
+	if err != nil {
+		return 0, err
+	}
+	if idle, _ := hint.Value().(bool); !idle {
+		return 0, nil
+	}
+	since, err := l.session.GetProperty("org.freedesktop.login1.Session.IdleSinceHint")
+	if err != nil {
+		return 0, err
+	}
+	// IdleSinceHint 是开始空闲时的微秒时间戳
+	micros, _ := since.Value().(uint64)
+	if micros == 0 {
+		return 0, nil
+	}
+	return time.Since(time.UnixMicro(int64(micros))), nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// newIdleSource 空闲检测目前只支持 Linux
+func newIdleSource() (idleSource, error) {
+	return nil, errors.New("当前系统不支持空闲检测")
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+)
+
+// fakeIdle 返回固定的无操作时长
+type fakeIdle struct {
+	idle time.Duration
+}
+
+func (f *fakeIdle) IdleTime() (time.Duration, error) {
+	return f.idle, nil
+}
+
+// setupTestUI 在测试应用中创建主界面，计时相关的函数会刷新这些控件
+func setupTestUI(t *testing.T) {
+	t.Helper()
+	logger = &Logger{Logger: log.New(io.Discard, "", 0)}
+	myApp = test.NewApp()
+	t.Cleanup(myApp.Quit)
+	window = myApp.NewWindow(appTitle)
+	setting = defaultSettings()
+	// 没有数据库，日期不变时不会去查询今天的统计
+	today = time.Now().Format("2006-01-02")
+	initResources()
+	initI18n()
+	loadColors()
+	createUI()
+	resetTimer()
+}
+
+// startWorking 模拟已经专注了 elapsed 的计时状态
+func startWorking(elapsed time.Duration) {
+	currentState = stateWorking
+	isRunning = true
+	total = 45 * time.Minute
+	runningBase = elapsed
+	lastStartClock = awakeClock()
+	idlePaused = false
+}
+
+func TestCheckIdlePausesAfterIdleMinutes(t *testing.T) {
+	setupTestUI(t)
+	setting.Integrations.IdleMinutes = 5
+	source := &fakeIdle{}
+	startWorking(20 * time.Minute)
+
+	source.idle = 4 * time.Minute
+	pollIdle(source)
+	if !isRunning || idlePaused {
+		t.Fatalf("paused after %s idle, want running until %d minutes", source.idle, setting.Integrations.IdleMinutes)
+	}
+
+	source.idle = 6 * time.Minute
+	pollIdle(source)
+	if isRunning || !idlePaused || currentState != statePause {
+		t.Fatalf("still running after %s idle", source.idle)
+	}
+	if idleCounted != 6*time.Minute {
+		t.Errorf("idleCounted = %s, want 6m", idleCounted)
+	}
+	if d := time.Since(idleSince) - 6*time.Minute; d < 0 || d > time.Second {
+		t.Errorf("idleSince is %s off from 6m ago", d)
+	}
+}
+
+func TestCheckIdleIgnoresBreakAndPause(t *testing.T) {
+	setupTestUI(t)
+	setting.Integrations.IdleMinutes = 5
+	source := &fakeIdle{idle: time.Hour}
+
+	startWorking(10 * time.Minute)
+	currentState = stateBreaking
+	pollIdle(source)
+	if !isRunning || idlePaused {
+		t.Error("paused during a break")
+	}
+
+	startWorking(10 * time.Minute)
+	isRunning = false
+	currentState = statePause
+	pollIdle(source)
+	if idlePaused {
+		t.Error("idle pause while already paused by the user")
+	}
+}
+
+func TestCheckIdleCountedIsCappedBySession(t *testing.T) {
+	setupTestUI(t)
+	setting.Integrations.IdleMinutes = 5
+	startWorking(3 * time.Minute)
+
+	pollIdle(&fakeIdle{idle: 8 * time.Minute})
+	if !idlePaused {
+		t.Fatal("not paused")
+	}
+	if idleCounted != totalRunningTime {
+		t.Errorf("idleCounted = %s, want capped at running time %s", idleCounted, totalRunningTime)
+	}
+}
+
+func TestApplyIdleReturn(t *testing.T) {
+	tests := []struct {
+		name string
+		keep bool
+		want time.Duration
+	}{
+		// 专注 20 分钟时离开，其中 6 分钟在暂停前已计入，一共离开 10 分钟
+		{"keep", true, 24 * time.Minute},
+		{"discard", false, 14 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total = 45 * time.Minute
+			totalRunningTime = 20 * time.Minute
+			idleCounted = 6 * time.Minute
+
+			applyIdleReturn(tt.keep, 10*time.Minute)
+			if totalRunningTime != tt.want {
+				t.Errorf("totalRunningTime = %s, want %s", totalRunningTime, tt.want)
+			}
+			if remaining != total-tt.want {
+				t.Errorf("remaining = %s, want %s", remaining, total-tt.want)
+			}
+		})
+	}
+}
+
+func TestReturnAfterIdleShowsDialog(t *testing.T) {
+	setupTestUI(t)
+	setting.Integrations.IdleMinutes = 5
+	startWorking(20 * time.Minute)
+	pollIdle(&fakeIdle{idle: 6 * time.Minute})
+
+	// 离开期间继续查询不会恢复
+	pollIdle(&fakeIdle{idle: 7 * time.Minute})
+	if !idlePaused {
+		t.Fatal("resumed while still away")
+	}
+
+	pollIdle(&fakeIdle{idle: time.Second})
+	if idlePaused {
+		t.Error("still waiting after the user came back")
+	}
+	if currentState != statePause {
+		t.Error("resumed before the user answered the dialog")
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// 主界面布局方式
+const (
+	layoutAuto       = "auto"
+	layoutHorizontal = "horizontal"
+	layoutVertical   = "vertical"
+)
+
+var layoutOrder = []string{layoutAuto, layoutHorizontal, layoutVertical}
+
+const (
+	// 缩放为 1 时各元素的尺寸
+	baseTimeTextSize  = 120
+	baseStateTextSize = 24
+	baseStatTextSize  = 20
+	baseStateIconSize = 32
+	baseStatIconSize  = 20
+	baseStateSpacing  = -25
+
+	// 横向和纵向布局的最小尺寸
+	horizontalMinWidth  = 110 + 200 + 112
+	horizontalMinHeight = 180
+	verticalMinWidth    = 220
+	verticalMinHeight   = 320
+
+	minUIScale = 0.3
+	maxUIScale = 4
+	minZoom    = 0.5
+	maxZoom    = 2
+)
+
+var (
+	uiScale       float32 = 1
+	scaleDirty    bool
+	mainContainer *fyne.Container
+	stateSpacer   *NegativeSpacer
+	countIcon     *canvas.Image
+	timeIcon      *canvas.Image
+)
+
+// responsiveLayout 按窗口大小在横向三栏和纵向上下排列之间切换，并按中间区域大小缩放字体
+type responsiveLayout struct {
+	horizontal *ProportionalLayout
+	bar        *fyne.Container
+	stats      *fyne.Container
+	variant    string
+}
+
+func newResponsiveLayout(bar, stats *fyne.Container) *responsiveLayout {
+	return &responsiveLayout{
+		horizontal: newProportionalLayout(0.15, 0.7, 0.15, 110, 200, 112),
+		bar:        bar,
+		stats:      stats,
+		variant:    layoutHorizontal,
+	}
+}
+
+func (l *responsiveLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	if len(objects) != 3 {
+		return // 必须有三个对象：工具栏、计时、统计
+	}
+	l.setVariant(layoutVariant(size))
+
+	// 纵向布局中统计栏高度随缩放变化，缩放改变后再排一次
+	for i := 0; i < 2; i++ {
+		var center fyne.Size
+		if l.variant == layoutVertical {
+			barHeight := objects[0].MinSize().Height
+			statsHeight := objects[2].MinSize().Height
+			center = fyne.NewSize(size.Width, fyne.Max(size.Height-barHeight-statsHeight, 0))
+
+			objects[0].Resize(fyne.NewSize(size.Width, barHeight))
+			objects[0].Move(fyne.NewPos(0, 0))
+			objects[1].Resize(center)
+			objects[1].Move(fyne.NewPos(0, barHeight))
+			objects[2].Resize(fyne.NewSize(size.Width, statsHeight))
+			objects[2].Move(fyne.NewPos(0, barHeight+center.Height))
+		} else {
+			l.horizontal.Layout(objects, size)
+			center = objects[1].Size()
+		}
+
+		if !updateUIScale(center) {
+			return
+		}
+		objects[1].Refresh()
+		objects[2].Refresh()
+	}
+}
+
+// MinSize 不包含计时区域，字体随窗口缩放，否则窗口会被越撑越大
+func (l *responsiveLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	switch setting.Appearance.Layout {
+	case layoutHorizontal:
+		return fyne.NewSize(horizontalMinWidth, horizontalMinHeight)
+	case layoutVertical:
+		return fyne.NewSize(verticalMinWidth, verticalMinHeight)
+	default:
+		return fyne.NewSize(verticalMinWidth, horizontalMinHeight)
+	}
+}
+
+// setVariant 切换布局时调整工具栏和统计栏的排列
+func (l *responsiveLayout) setVariant(variant string) {
+	if variant == l.variant {
+		return
+	}
+	l.variant = variant
+	if variant == layoutVertical {
+		l.bar.Layout = layout.NewGridLayoutWithColumns(2)
+		l.stats.Layout = layout.NewGridLayoutWithColumns(2)
+	} else {
+		l.bar.Layout = layout.NewVBoxLayout()
+		l.stats.Layout = layout.NewVBoxLayout()
+	}
+	l.bar.Refresh()
+	l.stats.Refresh()
+}
+
+// layoutVariant 自动模式下窄窗口或竖长窗口使用纵向布局
+func layoutVariant(size fyne.Size) string {
+	switch setting.Appearance.Layout {
+	case layoutHorizontal, layoutVertical:
+		return setting.Appearance.Layout
+	}
+	if size.Width < horizontalMinWidth || size.Height > size.Width {
+		return layoutVertical
+	}
+	return layoutHorizontal
+}
+
+// updateUIScale 根据计时区域大小和用户缩放计算字体缩放比例，返回比例是否改变
+func updateUIScale(area fyne.Size) bool {
+	base := timeDisplayBase()
+	if base.Width <= 0 || base.Height <= 0 || area.Width <= 0 || area.Height <= 0 {
+		return false
+	}
+	scale := fyne.Min(area.Width/base.Width, area.Height/base.Height) * setting.Appearance.Zoom
+	scale = fyne.Max(minUIScale, fyne.Min(maxUIScale, scale))
+	if diff := scale - uiScale; !scaleDirty && diff > -0.01 && diff < 0.01 {
+		return false
+	}
+	uiScale = scale
+	scaleDirty = false
+	applyUIScale()
+	return true
+}
+
+// timeDisplayBase 缩放为 1 时计时区域需要的大小，留出少量边距
+func timeDisplayBase() fyne.Size {
+	state := fyne.MeasureText(stateText.Text, baseStateTextSize, stateText.TextStyle)
+	height := state.Height + baseStateSpacing
+	switch setting.Appearance.RingMode {
+	case ringWrap:
+		return fyne.NewSize(ringWrapSize, ringWrapSize+height).AddWidthHeight(16, 16)
+	case ringReplace:
+		return fyne.NewSize(ringReplaceSize, ringReplaceSize+height).AddWidthHeight(16, 16)
+	}
+	sample := "00:00"
+	if len(timeText.Text) > len(sample) {
+		sample = "0:00:00"
+	}
+	text := fyne.MeasureText(sample, baseTimeTextSize, timeText.TextStyle)
+	return fyne.NewSize(fyne.Max(text.Width, state.Width), text.Height+height).AddWidthHeight(16, 16)
+}
+
+// applyUIScale 按当前缩放比例设置字体、图标和进度环大小
+func applyUIScale() {
+	if timeText == nil {
+		return
+	}
+	switch setting.Appearance.RingMode {
+	case ringWrap:
+		timeText.TextSize = ringWrapTextSize * uiScale
+	default:
+		timeText.TextSize = baseTimeTextSize * uiScale
+	}
+	if timeRing != nil {
+		size := float32(ringReplaceSize)
+		if setting.Appearance.RingMode == ringWrap {
+			size = ringWrapSize
+		}
+		timeRing.minSize = fyne.NewSize(size*uiScale, size*uiScale)
+		timeRing.Refresh()
+	}
+	timeText.Refresh()
+
+	stateText.TextSize = baseStateTextSize * uiScale
+	stateText.Refresh()
+	statImage.SetMinSize(fyne.NewSize(baseStateIconSize*uiScale, baseStateIconSize*uiScale))
+	if stateSpacer != nil {
+		stateSpacer.height = baseStateSpacing * uiScale
+		stateSpacer.Refresh()
+	}
+
+	statCountText.TextSize = baseStatTextSize * uiScale
+	statCountText.Refresh()
+	statTimeText.TextSize = baseStatTextSize * uiScale
+	statTimeText.Refresh()
+	for _, icon := range []*canvas.Image{countIcon, timeIcon} {
+		if icon != nil {
+			icon.SetMinSize(fyne.NewSize(baseStatIconSize*uiScale, baseStatIconSize*uiScale))
+		}
+	}
+	if timeDisplay != nil {
+		timeDisplay.Refresh()
+	}
+}
+
+// refreshMainLayout 布局或缩放设置变化后重新排列主界面
+func refreshMainLayout() {
+	if mainContainer == nil {
+		return
+	}
+	scaleDirty = true
+	mainContainer.Refresh()
+}
+
+func layoutLabel(variant string) string {
+	return tr("layout." + variant)
+}
+
+// createLayoutSettings 设置窗口中的布局方式和界面缩放
+func createLayoutSettings() fyne.CanvasObject {
+	labels := make([]string, 0, len(layoutOrder))
+	for _, variant := range layoutOrder {
+		labels = append(labels, layoutLabel(variant))
+	}
+	layoutSelect := widget.NewSelect(labels, func(selected string) {
+		for _, variant := range layoutOrder {
+			if layoutLabel(variant) == selected && variant != setting.Appearance.Layout {
+				setting.Appearance.Layout = variant
+				refreshMainLayout()
+			}
+		}
+	})
+	variant := setting.Appearance.Layout
+	if variant != layoutHorizontal && variant != layoutVertical {
+		variant = layoutAuto
+	}
+	layoutSelect.SetSelected(layoutLabel(variant))
+
+	zoomLabel := widget.NewLabel(formatZoom(setting.Appearance.Zoom))
+	zoomSlider := widget.NewSlider(minZoom, maxZoom)
+	zoomSlider.Step = 0.1
+	zoomSlider.SetValue(float64(setting.Appearance.Zoom))
+	zoomSlider.OnChanged = func(value float64) {
+		zoomLabel.SetText(formatZoom(float32(value)))
+	}
+	zoomSlider.OnChangeEnded = func(value float64) {
+		setting.Appearance.Zoom = float32(value)
+		refreshMainLayout()
+	}
+
+	return container.NewBorder(nil, nil, layoutSelect, zoomLabel, zoomSlider)
+}
+
+func formatZoom(zoom float32) string {
+	return fmt.Sprintf("%d%%", int(zoom*100+0.5))
+}
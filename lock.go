@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// sessionEvent 系统会话事件
+type sessionEvent int
+
+const (
+	sessionLock sessionEvent = iota
+	sessionUnlock
+	sessionSleep
+	sessionWake
+)
+
+// sessionEventSource 提供锁屏、解锁、休眠、唤醒事件，不同平台各自实现
+type sessionEventSource interface {
+	Events() (<-chan sessionEvent, error)
+	Close() error
+}
+
+var (
+	screenLocked bool
+	// lockPaused 因锁屏或休眠而暂停，解锁后自动继续
+	lockPaused bool
+)
+
+// startLockMonitor 监听系统会话事件，不支持的平台直接忽略
+func startLockMonitor() {
+	source, err := newSessionEventSource()
+	if err != nil {
+		logInfo("session events unavailable: %v", err)
+		return
+	}
+	events, err := source.Events()
+	if err != nil {
+		logError("subscribe session events error", err)
+		source.Close()
+		return
+	}
+	go func() {
+		for event := range events {
+			event := event
+			fyne.Do(func() {
+				handleSessionEvent(event)
+			})
+		}
+	}()
+}
+
+// handleSessionEvent 锁屏或休眠时暂停专注，解锁后继续；休眠前已锁屏的等解锁再继续
+func handleSessionEvent(event sessionEvent) {
+	switch event {
+	case sessionLock:
+		screenLocked = true
+		pauseForLock()
+	case sessionSleep:
+		pauseForLock()
+	case sessionUnlock:
+		screenLocked = false
+		resumeAfterLock()
+	case sessionWake:
+		if !screenLocked {
+			resumeAfterLock()
+		}
+	}
+}
+
+func pauseForLock() {
+	if !setting.Integrations.LockPause || currentState != stateWorking || !isRunning {
+		return
+	}
+	pauseTimer()
+	lockPaused = true
+	logInfo("screen locked, timer paused")
+}
+
+func resumeAfterLock() {
+	if !lockPaused {
+		return
+	}
+	lockPaused = false
+	if currentState == statePause {
+		logInfo("screen unlocked, timer resumed")
+		startTimer()
+	}
+}
+
+func createLockSettings() fyne.CanvasObject {
+	lockCheck := widget.NewCheck(tr("lock.check"), func(checked bool) {
+		setting.Integrations.LockPause = checked
+	})
+	lockCheck.SetChecked(setting.Integrations.LockPause)
+	return lockCheck
+}
@@ -0,0 +1,101 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	logindName      = "org.freedesktop.login1"
+	logindPath      = "/org/freedesktop/login1"
+	logindManager   = "org.freedesktop.login1.Manager"
+	logindSessionIf = "org.freedesktop.login1.Session"
+)
+
+// logindEvents 订阅 logind 的 Lock/Unlock 和 PrepareForSleep 信号
+type logindEvents struct {
+	conn        *dbus.Conn
+	sessionPath dbus.ObjectPath
+}
+
+func newSessionEventSource() (sessionEventSource, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+	return newLogindEvents(conn), nil
+}
+
+// newLogindEvents 使用给定的总线连接，测试时可以连到私有的 dbus-daemon
+func newLogindEvents(conn *dbus.Conn) *logindEvents {
+	var path dbus.ObjectPath
+	manager := conn.Object(logindName, logindPath)
+	if err := manager.Call(logindManager+".GetSessionByPID", 0, uint32(os.Getpid())).Store(&path); err != nil {
+		// 找不到所属会话时接收所有会话的锁屏信号
+		logError("get logind session error", err)
+	}
+	return &logindEvents{conn: conn, sessionPath: path}
+}
+
+func (l *logindEvents) Events() (<-chan sessionEvent, error) {
+	sessionMatch := []dbus.MatchOption{dbus.WithMatchInterface(logindSessionIf)}
+	if l.sessionPath != "" {
+		sessionMatch = append(sessionMatch, dbus.WithMatchObjectPath(l.sessionPath))
+	}
+	if err := l.conn.AddMatchSignal(sessionMatch...); err != nil {
+		return nil, err
+	}
+	if err := l.conn.AddMatchSignal(
+		dbus.WithMatchInterface(logindManager),
+		dbus.WithMatchMember("PrepareForSleep"),
+	); err != nil {
+		return nil, err
+	}
+
+	signals := make(chan *dbus.Signal, 10)
+	l.conn.Signal(signals)
+	events := make(chan sessionEvent)
+	go func() {
+		defer close(events)
+		for signal := range signals {
+			if event, ok := l.toEvent(signal); ok {
+				events <- event
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (l *logindEvents) toEvent(signal *dbus.Signal) (sessionEvent, bool) {
+	switch signal.Name {
+	case logindSessionIf + ".Lock", logindSessionIf + ".Unlock":
+		if l.sessionPath != "" && signal.Path != l.sessionPath {
+			return 0, false
+		}
+		if signal.Name == logindSessionIf+".Lock" {
+			return sessionLock, true
+		}
+		return sessionUnlock, true
+	case logindManager + ".PrepareForSleep":
+		// 参数为 true 表示即将休眠，false 表示已唤醒
+		if len(signal.Body) == 0 {
+			return 0, false
+		}
+		sleeping, ok := signal.Body[0].(bool)
+		if !ok {
+			return 0, false
+		}
+		if sleeping {
+			return sessionSleep, true
+		}
+		return sessionWake, true
+	}
+	return 0, false
+}
+
+func (l *logindEvents) Close() error {
+	return l.conn.Close()
+}
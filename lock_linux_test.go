@@ -0,0 +1,136 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	testSessionPath  = dbus.ObjectPath("/org/freedesktop/login1/session/_31")
+	otherSessionPath = dbus.ObjectPath("/org/freedesktop/login1/session/_32")
+)
+
+func TestLogindToEvent(t *testing.T) {
+	tests := []struct {
+		name        string
+		sessionPath dbus.ObjectPath
+		signal      *dbus.Signal
+		want        sessionEvent
+		ok          bool
+	}{
+		{"lock", testSessionPath, sessionSignal(testSessionPath, "Lock"), sessionLock, true},
+		{"unlock", testSessionPath, sessionSignal(testSessionPath, "Unlock"), sessionUnlock, true},
+		{"lock other session", testSessionPath, sessionSignal(otherSessionPath, "Lock"), 0, false},
+		{"unlock other session", testSessionPath, sessionSignal(otherSessionPath, "Unlock"), 0, false},
+		{"lock unknown session", "", sessionSignal(otherSessionPath, "Lock"), sessionLock, true},
+		{"sleep", testSessionPath, sleepSignal(true), sessionSleep, true},
+		{"wake", testSessionPath, sleepSignal(false), sessionWake, true},
+		{"sleep without body", testSessionPath, sleepSignal(), 0, false},
+		{"sleep with wrong type", testSessionPath, sleepSignal("true"), 0, false},
+		{"other member", testSessionPath, sessionSignal(testSessionPath, "PauseDevice"), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &logindEvents{sessionPath: tt.sessionPath}
+			got, ok := l.toEvent(tt.signal)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("toEvent() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// TestLogindEventsOnPrivateBus 在私有的 dbus-daemon 上发送 logind 的信号
+func TestLogindEventsOnPrivateBus(t *testing.T) {
+	logger = &Logger{Logger: log.New(io.Discard, "", 0)}
+	address := startPrivateBus(t)
+
+	listen, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := newLogindEvents(listen)
+	defer source.Close()
+	// 私有总线上没有 logind，指定一个会话测试过滤
+	source.sessionPath = testSessionPath
+	events, err := source.Events()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emit, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emit.Close()
+	signals := []struct {
+		path   dbus.ObjectPath
+		name   string
+		values []any
+	}{
+		{otherSessionPath, logindSessionIf + ".Lock", nil},
+		{testSessionPath, logindSessionIf + ".Lock", nil},
+		{logindPath, logindManager + ".PrepareForSleep", []any{true}},
+		{logindPath, logindManager + ".PrepareForSleep", []any{false}},
+		{testSessionPath, logindSessionIf + ".Unlock", nil},
+	}
+	for _, s := range signals {
+		if err := emit.Emit(s.path, s.name, s.values...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, want := range []sessionEvent{sessionLock, sessionSleep, sessionWake, sessionUnlock} {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Fatalf("event = %v, want %v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for event %v", want)
+		}
+	}
+}
+
+func sessionSignal(path dbus.ObjectPath, member string) *dbus.Signal {
+	return &dbus.Signal{Path: path, Name: logindSessionIf + "." + member}
+}
+
+func sleepSignal(body ...any) *dbus.Signal {
+	return &dbus.Signal{Path: logindPath, Name: logindManager + ".PrepareForSleep", Body: body}
+}
+
+// startPrivateBus 启动一个只供本次测试使用的会话总线，返回连接地址
+func startPrivateBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+	cmd := exec.Command(daemon, "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read dbus-daemon address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// newSessionEventSource 锁屏事件目前只支持 Linux
+func newSessionEventSource() (sessionEventSource, error) {
+	return nil, errors.New("当前系统不支持锁屏事件")
+}
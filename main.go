@@ -14,6 +14,7 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
 	"github.com/faiface/beep/mp3"
 	"github.com/faiface/beep/speaker"
 	_ "github.com/mattn/go-sqlite3"
@@ -45,6 +46,9 @@ const (
 
 const (
 	defaultEmpty = ""
+	databaseFile = "pomodoro.db"
+	// 提前提醒的铃声音量，相对原始音量降低，避免打断思路
+	warnSoundVolume = -1.5
 )
 
 var (
@@ -75,7 +79,8 @@ var (
 	total            time.Duration
 	totalRunningTime time.Duration
 	startTime        time.Time
-	lastStartTime    time.Time
+	lastStartClock   time.Duration
+	runningBase      time.Duration
 	isRunning        bool
 	timeText         *canvas.Text
 	stateText        *canvas.Text
@@ -97,6 +102,12 @@ var (
 	db               *sql.DB
 	settingsMutex    sync.Mutex
 	windowClosing    bool
+	warnedOffsets    = make(map[int]bool)
+	flashDimmed      bool
+	adjustedTime     time.Duration
+	overtime         bool
+	lastWorkTime     time.Duration
+	lastWorkPlanned  time.Duration
 )
 
 const (
@@ -110,10 +121,21 @@ const (
             type TEXT NOT NULL
         )
     `
-	INSERT_SQL   = "INSERT INTO task_record (date, start_time, end_time, duration, type) VALUES (?, ?, ?, ?, ?)"
-	SELECT_SQL   = "SELECT id, date, start_time, end_time, duration, type FROM task_record WHERE date = ? ORDER BY start_time"
-	COUNT_SQL    = "select count(*) FROM task_record WHERE date = ? "
-	DURATION_SQL = "SELECT SUM(duration) FROM task_record WHERE date = ? "
+	INSERT_SQL   = "INSERT INTO task_record (date, start_time, end_time, duration, type, adjust, skipped, sleep_gap) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	SELECT_SQL   = "SELECT id, date, start_time, end_time, duration, type, adjust, skipped FROM task_record WHERE date = ? ORDER BY start_time"
+	COUNT_SQL    = "select count(*) FROM task_record WHERE date = ? AND type = 'pomodoro' AND skipped = 0"
+	DURATION_SQL = "SELECT SUM(duration) FROM task_record WHERE date = ? AND type = 'pomodoro' AND skipped = 0"
+	TYPE_SQL     = "SELECT type, count(*), SUM(duration) FROM task_record WHERE date = ? AND skipped = 0 GROUP BY type"
+
+	CREATE_SKIP_SQL = `
+        CREATE TABLE IF NOT EXISTS break_skip (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            date TEXT NOT NULL,
+            skip_time TEXT NOT NULL
+        )
+    `
+	INSERT_SKIP_SQL = "INSERT INTO break_skip (date, skip_time) VALUES (?, ?)"
+	COUNT_SKIP_SQL  = "SELECT count(*) FROM break_skip WHERE date = ? "
 )
 
 type Logger struct {
@@ -137,23 +159,9 @@ type taskRecord struct {
 	EndTime   time.Time `json:"endTime"`
 	Duration  int       `json:"duration"`
 	Type      string    `json:"type"`
-}
-
-type settings struct {
-	WorkTime       int    `json:"workTime"`
-	BreakTime      int    `json:"breakTime"`
-	WorkInformPath string `json:"workInformPath"`
-	//BreakInformPath string  `json:"breakInformPath"`
-	Height         float32 `json:"height"`
-	Width          float32 `json:"width"`
-	WorkColorText  string  `json:"workColorText"`
-	BreakColorText string  `json:"breakColorText"`
-	NoteColorText  string  `json:"noteColorText"`
-	BgColorText    string  `json:"BgColorText"`
-	StatColorText  string  `json:"statColorText"`
-	workPathText   *widget.Label
-	//breakPathText   *widget.Label
-	bgPathText *widget.Label
+	Adjust    int       `json:"adjust"`
+	Skipped   bool      `json:"skipped"`
+	SleepGap  int       `json:"sleepGap"`
 }
 
 var defaultBgColor color.Color = color.RGBA{R: 255, G: 255, B: 255, A: 255}
@@ -168,18 +176,22 @@ var statColor color.Color = defaultStatColor
 var breakColor color.Color = defaultBreakColor
 var workColor color.Color = defaultWorkColor
 
+// 心流模式超时计时的颜色
+var flowColor color.Color = color.RGBA{R: 142, G: 91, B: 181, A: 255}
+
 func main() {
 
 	logger = newDefaultLogger()
 
 	myApp = app.NewWithID("XTimer")
 
-	window = myApp.NewWindow("XTimer")
+	window = myApp.NewWindow(appTitle)
 
 	myApp.Lifecycle().SetOnStopped(func() {
 		if ticker != nil {
 			ticker.Stop()
 		}
+		persistSession()
 		if db != nil {
 			err := db.Close()
 			if err != nil {
@@ -190,34 +202,10 @@ func main() {
 
 	initResources()
 	loadSettings()
+	initI18n()
 
-	if setting.WorkColorText != "" {
-		toColor, err := hexToColor(setting.WorkColorText)
-		if err != nil {
-			workColor = toColor
-		}
-	}
-
-	if setting.BreakColorText != "" {
-		toColor, err := hexToColor(setting.BreakColorText)
-		if err != nil {
-			breakColor = toColor
-		}
-	}
-
-	if setting.NoteColorText != "" {
-		toColor, err := hexToColor(setting.NoteColorText)
-		if err != nil {
-			noteColor = toColor
-		}
-	}
-
-	if setting.StatColorText != "" {
-		toColor, err := hexToColor(setting.StatColorText)
-		if err != nil {
-			statColor = toColor
-		}
-	}
+	loadColors()
+	initTheme()
 
 	if err := initDatabase(); err != nil {
 		logError("init database error,", err)
@@ -225,22 +213,31 @@ func main() {
 		return
 	}
 
+	loadPendingSession()
+
 	today = time.Now().Format("2006-01-02")
 	pomodoroCount, _ = countRecordByDate(today)
 	pomodoroTime, _ = getTotalWorkTimeByDate(today)
 
 	overlay = canvas.NewRectangle(bgColor)
-	content = container.NewStack(overlay, createUI())
+	content = container.NewStack(overlay, createBackground(), createUI())
+	initTray()
+	applyShortcuts(window.Canvas())
+	registerGlobalHotkey(setting.Shortcuts.GlobalHotkey)
+	startIdleMonitor()
+	startLockMonitor()
 
 	window.SetCloseIntercept(func() {
-		setting.Width = window.Canvas().Size().Width
-		setting.Height = window.Canvas().Size().Height
+		saveWindowGeometry()
 		saveSettings()
+		if hideToTray() {
+			return
+		}
 		closeDialog := dialog.NewCustomConfirm(
-			"关闭确认",
-			"关闭",
-			"取消",
-			container.NewCenter(canvas.NewText("确定要关闭应用吗？", theme.TextColor())), func(confirmed bool) {
+			tr("dialog.closeTitle"),
+			tr("button.close"),
+			tr("button.cancel"),
+			container.NewCenter(canvas.NewText(tr("dialog.closeMessage"), theme.TextColor())), func(confirmed bool) {
 				if confirmed {
 					window.Close()
 					if settingsWindow != nil {
@@ -253,11 +250,18 @@ func main() {
 		closeDialog.Show()
 	})
 
-	resetTimer()
+	// 先设置默认图标，开启动态图标时 resetTimer 会替换它
 	window.SetIcon(logoImage)
-	window.Resize(fyne.NewSize(setting.Width, setting.Height))
+	resetTimer()
+	window.Resize(fyne.NewSize(setting.Window.Width, setting.Window.Height))
 	window.SetPadded(false)
 	window.SetContent(content)
+	myApp.Lifecycle().SetOnStarted(func() {
+		restoreWindowPosition()
+		if !offerSessionResume() {
+			autoStartOnLaunch()
+		}
+	})
 	window.ShowAndRun()
 
 }
@@ -284,49 +288,51 @@ func showSettingsWindow() {
 		return
 	}
 
-	settingsWindow = myApp.NewWindow("设置")
+	settingsWindow = myApp.NewWindow(tr("settings.title"))
 	settingsWindow.SetCloseIntercept(func() {
 		closeSettingsWindow()
 	})
 
-	settingsWindow.Resize(fyne.NewSize(500, 400))
-
-	settingsContent := createSettingsContent()
-
-	// 完整布局
-	content := container.NewVScroll(settingsContent)
+	settingsWindow.Resize(fyne.NewSize(560, 460))
+	settingsSnapshot = cloneSettings(setting)
 
-	settingsWindow.SetContent(content)
+	// 分页内容各自滚动
+	settingsWindow.SetContent(createSettingsContent())
 	settingsWindow.Show()
 }
 
+// refreshSettingsContent 主题等整体变化后重建设置窗口内容
+func refreshSettingsContent() {
+	if settingsWindow == nil {
+		return
+	}
+	settingsWindow.SetContent(createSettingsContent())
+}
+
 func createUI() fyne.CanvasObject {
 	toolbar := widget.NewToolbar(
 		widget.NewToolbarAction(theme.SettingsIcon(), func() {
 			showSettingsWindow()
 		}),
+		widget.NewToolbarAction(theme.ViewRestoreIcon(), toggleMiniMode),
+		widget.NewToolbarAction(theme.HistoryIcon(), showModeDialog),
 	)
 
 	doBarAction = widget.NewToolbarAction(theme.MediaPlayIcon(), toggleTimer)
 	doBar = widget.NewToolbar(doBarAction)
-	resetBar = widget.NewToolbar(widget.NewToolbarAction(theme.MediaStopIcon(),
-		func() {
-			informDialog := dialog.NewCustomConfirm("确认重置", "确定", "手滑",
-				container.NewCenter(canvas.NewText("重置将会清除当前状态和进度，确认吗？", workColor)), func(confirmed bool) {
-					if confirmed {
-						resetTimer()
-					}
-				}, window)
-			informDialog.Resize(fyne.NewSize(300, 250))
-			informDialog.Show()
-		}))
+	resetBar = widget.NewToolbar(widget.NewToolbarAction(theme.MediaStopIcon(), confirmReset))
+	adjustBar := widget.NewToolbar(
+		widget.NewToolbarAction(theme.MediaSkipNextIcon(), guardStrictBreak(skipTimer)),
+		widget.NewToolbarAction(theme.ContentAddIcon(), extendTimer),
+		widget.NewToolbarAction(theme.ContentRemoveIcon(), shortenTimer),
+	)
 
-	stateText = canvas.NewText("准备开始", noteColor)
-	stateText.TextSize = 24
+	stateText = canvas.NewText(tr("state.ready"), noteColor)
+	stateText.TextSize = baseStateTextSize
 
 	statImage = canvas.NewImageFromResource(pauseImage)
 	statImage.FillMode = canvas.ImageFillContain
-	statImage.SetMinSize(fyne.NewSize(32, 32))
+	statImage.SetMinSize(fyne.NewSize(baseStateIconSize, baseStateIconSize))
 
 	stateContent := container.NewCenter(
 		container.NewHBox(
@@ -335,20 +341,24 @@ func createUI() fyne.CanvasObject {
 		),
 	)
 
-	total = time.Duration(setting.WorkTime) * time.Minute
+	total = idleDuration()
 	remaining = total
 
 	timeText = canvas.NewText(formatDuration(remaining), workColor)
-	timeText.TextSize = 120
+	timeText.TextSize = baseTimeTextSize
 
 	statCountText = canvas.NewText(getPomodoroCount(), statColor)
-	statCountText.TextSize = 20
+	statCountText.TextSize = baseStatTextSize
 
 	statTimeText = canvas.NewText(getPomodoroTime(), statColor)
-	statTimeText.TextSize = 20
+	statTimeText.TextSize = baseStatTextSize
 
-	countIcon := widget.NewIcon(pomodoroImage)
-	timeIcon := widget.NewIcon(clockImage)
+	countIcon = canvas.NewImageFromResource(pomodoroImage)
+	countIcon.FillMode = canvas.ImageFillContain
+	countIcon.SetMinSize(fyne.NewSize(baseStatIconSize, baseStatIconSize))
+	timeIcon = canvas.NewImageFromResource(clockImage)
+	timeIcon.FillMode = canvas.ImageFillContain
+	timeIcon.SetMinSize(fyne.NewSize(baseStatIconSize, baseStatIconSize))
 
 	countItem := container.NewHBox(
 		countIcon,
@@ -364,28 +374,46 @@ func createUI() fyne.CanvasObject {
 		),
 	)
 
-	statsContainer := container.NewVBox(countItem, timeItem)
-	statsContainer = container.NewPadded(statsContainer)
+	statsContainer := container.NewVBox(countItem, timeItem, createPresetSelect())
+	barContainer := container.NewVBox(toolbar, resetBar, doBar, adjustBar)
 
-	barContainer := container.NewVBox(toolbar, resetBar, doBar)
-	barContainer = container.NewPadded(barContainer)
-
-	finalLayout := container.New(newProportionalLayout(0.15, 0.7, 0.15, 110, 200, 112),
-		barContainer,
+	// 横向时三栏排列，窄或竖长窗口改为上下排列，字体随窗口缩放
+	stateSpacer = NewNegativeSpacer(baseStateSpacing)
+	mainContainer = container.New(newResponsiveLayout(barContainer, statsContainer),
+		container.NewPadded(barContainer),
 		container.NewCenter(
 			container.NewVBox(
 				container.NewCenter(stateContent),
-				NewNegativeSpacer(-25),
-				container.NewCenter(timeText),
+				stateSpacer,
+				createTimeDisplay(),
 			),
 		),
-		statsContainer,
+		container.NewPadded(statsContainer),
 	)
 
-	return finalLayout
+	return mainContainer
+}
+
+func confirmReset() {
+	if freeTimerRunning() {
+		// 正计时和倒计时的停止即结束，按实际用时保存记录，不需要确认
+		finishFreeTimer()
+		return
+	}
+	informDialog := dialog.NewCustomConfirm(tr("reset.title"), tr("button.ok"), tr("reset.cancel"),
+		container.NewCenter(canvas.NewText(tr("reset.message"), workColor)), func(confirmed bool) {
+			if confirmed {
+				guardStrictBreak(resetTimer)()
+			}
+		}, window)
+	informDialog.Resize(fyne.NewSize(300, 250))
+	informDialog.Show()
 }
 
 func toggleTimer() {
+	if strictBreakLocked() {
+		return
+	}
 	if !isRunning {
 		startTimer()
 	} else {
@@ -395,23 +423,29 @@ func toggleTimer() {
 
 func startTimer() {
 	if currentState == stateIdle {
-		if nextState == stateWorking {
-			total = time.Duration(setting.WorkTime) * time.Minute
-			remaining = total
-			totalRunningTime = 0
-			startTime = time.Now()
-		}
-		if nextState == stateBreaking {
-			total = time.Duration(setting.WorkTime) * time.Minute
-			remaining = total
-			totalRunningTime = 0
-			startTime = time.Now()
-		}
-	}
-	lastStartTime = time.Now()
+		warnedOffsets = make(map[int]bool)
+		flashDimmed = false
+		adjustedTime = 0
+		overtime = false
+		total = idleDuration()
+		remaining = total
+		totalRunningTime = 0
+		sleepGap = 0
+		startTime = time.Now()
+	}
+	// 恢复的休息（如重启后继续上次的会话）也要显示严格休息窗口，已显示时不会重复打开
+	breaking := nextState == stateBreaking
+	// 每段计时以开始时刻为基准计算已用时间，不逐秒累加，避免误差和漏算
+	runningBase = totalRunningTime
+	lastStartClock = awakeClock()
+	resetClockCheck()
 	isRunning = true
 	transitionState(nextState)
 	doBarAction.SetIcon(theme.MediaPauseIcon())
+	if breaking {
+		showBreakOverlay()
+	}
+	persistSession()
 
 	if ticker == nil {
 		ticker = time.NewTicker(1 * time.Second)
@@ -425,31 +459,159 @@ func startTimer() {
 				return
 			}
 
-			currentRunTime := time.Since(lastStartTime)
-			lastStartTime = time.Now()
-			totalRunningTime = totalRunningTime + currentRunTime
+			if gap := detectSleep(); gap > 0 && !handleSleep(gap) {
+				return
+			}
+			totalRunningTime = elapsedRunning()
 			remaining = total - totalRunningTime
+			persistSessionPeriodically()
+
+			if timerMode() == modeStopwatch {
+				newText := formatDuration(totalRunningTime)
+				fyne.Do(func() {
+					updateTimeText(newText)
+					updateTrayTime(newText)
+				})
+				continue
+			}
 
 			if remaining <= 0 {
+				if currentState == stateWorking && setting.Timer.FlowMode && timerMode() == modePomodoro {
+					if !overtime {
+						overtime = true
+						go showFlowPrompt()
+					}
+					newText := "+" + formatDuration(-remaining)
+					fyne.Do(func() {
+						enterOvertime()
+						updateTimeText(newText)
+						updateTrayTime(newText)
+					})
+					continue
+				}
 				ticker.Stop()
 				timerComplete()
 				return
 			}
 			newText := formatDuration(remaining)
+			left := remaining
+			// warnedOffsets 在界面线程中调整时长时也会修改，提醒检查放到界面线程中执行
 			fyne.Do(func() {
+				checkWarning(left)
+				flashTimeText(left)
 				updateTimeText(newText)
+				updateTrayTime(newText)
+				updateBreakOverlay(newText)
 			})
 		}
 	}()
 }
 
+// elapsedRunning 返回本次会话已用时间，运行中按本段开始时刻计算
+func elapsedRunning() time.Duration {
+	if !isRunning {
+		return totalRunningTime
+	}
+	return runningBase + awakeClock() - lastStartClock
+}
+
 func updateTimeText(text string) {
 	timeText.Text = text
 	timeText.Refresh()
+	updateMini(text)
+	updateRing()
+	updateWindowTitle(text)
+	updateStatusIcon()
 	runtime.GC()
 }
 
+// checkWarning 按配置的提前量在会话结束前提醒，每个提前量每次会话只提醒一次
+func checkWarning(left time.Duration) {
+	for _, offset := range setting.Sounds.WarnOffsets {
+		warnAt := time.Duration(offset) * time.Second
+		if offset <= 0 || warnedOffsets[offset] || warnAt >= total {
+			continue
+		}
+		if left <= warnAt {
+			warnedOffsets[offset] = true
+			go showWarning(warnAt)
+		}
+	}
+}
+
+func showWarning(offset time.Duration) {
+	title := tr("warn.workTitle")
+	if currentState == stateBreaking {
+		title = tr("warn.breakTitle")
+	}
+	message := tr("warn.message", formatOffset(offset))
+	myApp.SendNotification(fyne.NewNotification(title, message))
+
+	soundFile := setting.Sounds.WarnInformPath
+	if soundFile == "" {
+		soundFile = setting.Sounds.WorkInformPath
+	}
+	playSoundWithVolume(soundFile, warnSoundVolume)
+}
+
+// flashTimeText 在最后几秒让倒计时明暗交替
+func flashTimeText(left time.Duration) {
+	if setting.Sounds.FlashSeconds <= 0 || left > time.Duration(setting.Sounds.FlashSeconds)*time.Second {
+		return
+	}
+	base := sessionColor()
+	flashDimmed = !flashDimmed
+	if flashDimmed {
+		dim := color.NRGBAModel.Convert(base).(color.NRGBA)
+		dim.A = 70
+		timeText.Color = dim
+	} else {
+		timeText.Color = base
+	}
+}
+
+func formatOffset(d time.Duration) string {
+	m := int(d / time.Minute)
+	s := int((d % time.Minute) / time.Second)
+	switch {
+	case m > 0 && s > 0:
+		return tr("offset.minSec", m, s)
+	case m > 0:
+		return trn("offset.min", m, m)
+	default:
+		return trn("offset.sec", s, s)
+	}
+}
+
+// showFlowPrompt 心流模式到点后只发通知，不打断当前的专注
+func showFlowPrompt() {
+	myApp.SendNotification(fyne.NewNotification(tr("flow.title"), tr("flow.message")))
+	playSoundWithVolume(setting.Sounds.WorkInformPath, warnSoundVolume)
+}
+
+func enterOvertime() {
+	if timeText.Color == flowColor {
+		return
+	}
+	timeText.Color = flowColor
+	stateText.Text = tr("state.flow")
+	stateText.Refresh()
+}
+
+// finishOvertime 结束心流超时，按实际专注时长完成本次番茄
+func finishOvertime() {
+	totalRunningTime = elapsedRunning()
+	isRunning = false
+	if ticker != nil {
+		ticker.Stop()
+	}
+	currentState = stateWorking
+	doBarAction.SetIcon(theme.MediaPlayIcon())
+	timerComplete()
+}
+
 func pauseTimer() {
+	totalRunningTime = elapsedRunning()
 	isRunning = false
 	transitionState(statePause)
 	doBarAction.SetIcon(theme.MediaPlayIcon())
@@ -457,17 +619,120 @@ func pauseTimer() {
 	if ticker != nil {
 		ticker.Stop()
 	}
+	persistSession()
 }
 
 func resetTimer() {
 	pauseTimer()
-	transitionState(stateIdle)
-	nextState = stateWorking
-	total = time.Duration(setting.WorkTime) * time.Minute
+	resetPhase()
+	overtime = false
+	lastWorkTime = 0
+	totalRunningTime = 0
+	total = idleDuration()
 	remaining = total
-	timeText.Text = formatDuration(remaining)
-	timeText.Color = workColor
+	transitionState(stateIdle)
+	timeText.Color = sessionColor()
+	updateTimeText(formatDuration(remaining))
 	doBarAction.SetIcon(theme.MediaPlayIcon())
+	updateTray()
+	clearSession()
+}
+
+// skipTimer 跳过当前阶段，直接进入下一阶段的准备状态，跳过的专注会标记在记录中且不计入统计
+func skipTimer() {
+	if overtime {
+		finishOvertime()
+		return
+	}
+	inSession := currentState != stateIdle
+	if timerMode() != modePomodoro {
+		// 正计时和倒计时没有下一阶段，跳过即提前完成并记录
+		if freeTimerRunning() {
+			finishFreeTimer()
+		}
+		return
+	}
+	pauseTimer()
+	if inSession && nextState == stateWorking {
+		saveSessionRecord(totalRunningTime, true)
+	}
+	advancePhase()
+	total = idleDuration()
+	remaining = total
+	transitionState(stateIdle)
+	timeText.Color = sessionColor()
+	updateTimeText(formatDuration(remaining))
+	updateTray()
+	clearSession()
+}
+
+// adjustTimer 延长或缩短当前会话，缩短时至少保留一分钟
+func adjustTimer(delta time.Duration) {
+	if currentState == stateIdle || timerMode() == modeStopwatch {
+		return
+	}
+	newTotal := total + delta
+	if newTotal-totalRunningTime < time.Minute {
+		if delta < 0 && remaining <= time.Minute {
+			return
+		}
+		newTotal = totalRunningTime + time.Minute
+	}
+	adjustedTime += newTotal - total
+	total = newTotal
+	remaining = total - totalRunningTime
+
+	// 延长后重新开放已经错过的提前提醒
+	for offset := range warnedOffsets {
+		if remaining > time.Duration(offset)*time.Second {
+			delete(warnedOffsets, offset)
+		}
+	}
+	// 延长后可能已离开闪烁区间或结束了心流超时，恢复正常的颜色和状态文字
+	if overtime && remaining > 0 {
+		overtime = false
+		transitionState(currentState)
+	}
+	flashDimmed = false
+	timeText.Color = sessionColor()
+	updateTimeText(formatDuration(remaining))
+	updateTrayTime(formatDuration(remaining))
+	persistSession()
+}
+
+// stopTimer 托盘的重置：正计时和倒计时按实际用时保存记录后结束，番茄钟直接重置
+func stopTimer() {
+	if freeTimerRunning() {
+		finishFreeTimer()
+		return
+	}
+	resetTimer()
+}
+
+// freeTimerRunning 正计时或倒计时已经开始，停止时需要保存记录
+func freeTimerRunning() bool {
+	return timerMode() != modePomodoro && currentState != stateIdle
+}
+
+// finishFreeTimer 结束正计时或倒计时，记录实际用时后回到准备状态
+func finishFreeTimer() {
+	totalRunningTime = elapsedRunning()
+	saveTaskRecord()
+	resetTimer()
+}
+
+func extendTimer() {
+	if strictBreakLocked() {
+		return
+	}
+	adjustTimer(time.Duration(setting.Timer.AdjustMinutes) * time.Minute)
+}
+
+func shortenTimer() {
+	if strictBreakLocked() {
+		return
+	}
+	adjustTimer(-time.Duration(setting.Timer.AdjustMinutes) * time.Minute)
 }
 
 func timerComplete() {
@@ -480,63 +745,110 @@ func transitionState(newState state) {
 	currentState = newState
 	switch newState {
 	case stateWorking:
-		total = time.Duration(setting.WorkTime) * time.Minute
-		stateText.Text = "专注中..."
+		stateText.Text = tr("state.working")
+		switch timerMode() {
+		case modeStopwatch:
+			stateText.Text = tr("state.stopwatch")
+		case modeCountdown:
+			stateText.Text = tr("state.countdown")
+		}
+		if timerMode() == modePomodoro && usingPlan() {
+			stateText.Text = phaseProgressText()
+		}
 		statImage.Resource = workingImage
 		stateText.Color = noteColor
-		timeText.Color = workColor
+		timeText.Color = sessionColor()
+		if overtime {
+			stateText.Text = tr("state.flow")
+			timeText.Color = flowColor
+		}
 	case stateBreaking:
-		total = time.Duration(setting.BreakTime) * time.Minute
-		stateText.Text = "休息中..."
+		stateText.Text = tr("state.breaking")
+		if usingPlan() {
+			stateText.Text = phaseProgressText()
+		}
 		statImage.Resource = breakingImage
 		stateText.Color = noteColor
-		timeText.Color = breakColor
+		timeText.Color = sessionColor()
 	case stateIdle:
-		stateText.Text = "准备开始"
+		closeBreakOverlay()
+		stateText.Text = tr("state.ready")
+		if timerMode() == modePomodoro && usingPlan() {
+			stateText.Text = tr("state.readyPhase", phaseProgressText())
+		}
 		statImage.Resource = pauseImage
 		stateText.Color = noteColor
 	case statePause:
-		stateText.Text = "暂个停..."
+		stateText.Text = tr("state.paused")
 		statImage.Resource = pauseImage
 		stateText.Color = noteColor
 	}
 
 	statImage.Refresh()
 	stateText.Refresh()
+	updateBackground()
+	refreshPresetSelect()
+	updateTray()
+	updateMini(timeText.Text)
+	updateWindowTitle(timeText.Text)
 }
 
 func showNotification() {
 	var title, message string
 	var soundFile string
 	newText := formatDuration(remaining)
+	if timerMode() == modeCountdown {
+		showCountdownNotification()
+		return
+	}
+	finished := currentPhase()
+	soundFile = finished.Sound
+	if soundFile == "" {
+		soundFile = setting.Sounds.WorkInformPath
+	}
 	if currentState == stateWorking {
-		title = "工作完成了！"
-		message = "辛苦了，休息一会吧！"
-		nextState = stateBreaking
-		soundFile = setting.WorkInformPath
+		title = tr("done.workTitle")
+		message = tr("done.workMessage")
+		lastWorkTime = totalRunningTime
+		// 按延长或缩短后的计划时长换算等比休息
+		lastWorkPlanned = total
+		overtime = false
 		updatePomodoro()
 		saveTaskRecord()
 		checkAndRefreshToday()
-		newText = formatDuration(time.Duration(setting.BreakTime) * time.Minute)
 	} else {
-		title = "继续工作了！"
-		message = "休息结束，要工作了，加油！"
-		nextState = stateWorking
-		soundFile = setting.WorkInformPath
-		newText = formatDuration(time.Duration(setting.WorkTime) * time.Minute)
+		title = tr("done.breakTitle")
+		message = tr("done.breakMessage")
+	}
+	advancePhase()
+	if usingPlan() {
+		message = tr("done.phaseMessage", finished.Label, phaseProgressText())
 	}
+	newText = formatDuration(idleDuration())
 
 	fyne.Do(func() {
+		closeBreakOverlay()
+		flashDimmed = false
+		timeText.Color = sessionColor()
 		updateTimeText(newText)
 	})
 
 	go playSound(soundFile)
 
 	currentState = stateIdle
+	clearSession()
+	fyne.Do(updateTray)
+
+	if shouldAutoStart() {
+		fyne.Do(func() {
+			showAutoStartCountdown(title, message)
+		})
+		return
+	}
 	informDialog := dialog.NewCustomConfirm(
 		title,
-		"好的",
-		"就不",
+		tr("done.start"),
+		tr("done.later"),
 		container.NewCenter(canvas.NewText(message, theme.TextColor())),
 		func(confirmed bool) {
 			if confirmed {
@@ -559,8 +871,19 @@ func showNotification() {
 	})
 }
 
+// showCountdownNotification 倒计时结束只提醒和记录，不进入休息
+func showCountdownNotification() {
+	saveTaskRecord()
+	go playSound(setting.Sounds.WorkInformPath)
+	fyne.Do(func() {
+		resetTimer()
+		dialog.ShowInformation(tr("countdown.title"), trn("countdown.message", setting.Timer.CountdownMinutes, setting.Timer.CountdownMinutes), window)
+		window.RequestFocus()
+	})
+}
+
 func updatePomodoro() {
-	pomodoroTime += int(math.Ceil(total.Minutes()))
+	pomodoroTime += int(math.Round(totalRunningTime.Minutes()))
 	pomodoroCount++
 
 	fyne.Do(func() {
@@ -577,7 +900,7 @@ func checkAndRefreshToday() {
 	if today != currentDay {
 		today = currentDay
 		pomodoroCount, _ = countRecordByDate(today)
-		pomodoroTime, _ = countRecordByDate(today)
+		pomodoroTime, _ = getTotalWorkTimeByDate(today)
 		fyne.Do(func() {
 			statTimeText.Text = getPomodoroTime()
 			statCountText.Text = getPomodoroCount()
@@ -590,12 +913,20 @@ func checkAndRefreshToday() {
 }
 
 func saveTaskRecord() {
+	saveSessionRecord(totalRunningTime, false)
+}
+
+// saveSessionRecord 记录一次专注，同时记下延长/缩短的分钟数和是否被跳过
+func saveSessionRecord(duration time.Duration, skipped bool) {
 	record := taskRecord{
 		Date:      startTime.Format("2006-01-02"),
 		StartTime: startTime,
 		EndTime:   time.Now(),
-		Duration:  int(math.Ceil(total.Minutes())),
-		Type:      "pomodoro",
+		Duration:  int(math.Round(duration.Minutes())),
+		Type:      timerMode(),
+		Adjust:    int(math.Round(adjustedTime.Minutes())),
+		Skipped:   skipped,
+		SleepGap:  int(sleepGap.Seconds()),
 	}
 	if err := addTimeRecord(record); err != nil {
 		logInfo("insert task record error.", record, err)
@@ -604,6 +935,13 @@ func saveTaskRecord() {
 
 func formatDuration(d time.Duration) string {
 	d = d.Round(time.Second)
+	if d >= time.Hour {
+		h := d / time.Hour
+		d -= h * time.Hour
+		m := d / time.Minute
+		d -= m * time.Minute
+		return fmt.Sprintf("%d:%02d:%02d", h, m, d/time.Second)
+	}
 	m := d / time.Minute
 	d -= m * time.Minute
 	s := d / time.Second
@@ -611,281 +949,310 @@ func formatDuration(d time.Duration) string {
 }
 
 func getPomodoroCount() string {
-	return fmt.Sprintf(": %d个", pomodoroCount)
+	return trn("stat.count", pomodoroCount, pomodoroCount)
 }
 
 func getPomodoroTime() string {
-	return fmt.Sprintf(": %d分", pomodoroTime)
+	return trn("stat.time", pomodoroTime, pomodoroTime)
 }
 
 func loadSettings() {
 
-	setting = &settings{
-		WorkTime:       45,
-		BreakTime:      15,
-		WorkInformPath: defaultEmpty,
-		WorkColorText:  colorToHex(workColor),
-		BreakColorText: colorToHex(breakColor),
-		NoteColorText:  colorToHex(noteColor),
-		StatColorText:  colorToHex(statColor),
-		BgColorText:    colorToHex(bgColor),
-		Width:          430,
-		Height:         238,
-	}
+	setting = defaultSettings()
 
-	if _, err := os.Stat("settings.json"); os.IsNotExist(err) {
+	if _, err := os.Stat(settingsFile); os.IsNotExist(err) {
 		logError("配置文件打开失败:", err)
 		return
 	}
 
-	data, err := os.ReadFile("settings.json")
+	data, err := os.ReadFile(settingsFile)
 	if err != nil {
 		logError("读取设置失败:", err)
 		return
 	}
 
-	if err := json.Unmarshal(data, &setting); err != nil {
+	content, migrated, err := migrateSettings(data)
+	if err != nil {
 		logError("解析设置失败:", err)
+		return
 	}
-
-	if setting.WorkTime == 0 {
-		setting.WorkTime = 45
-	}
-	if setting.BreakTime == 0 {
-		setting.BreakTime = 15
-	}
-	if setting.StatColorText == "" {
-		setting.StatColorText = colorToHex(statColor)
-	}
-	if setting.WorkColorText == "" {
-		setting.WorkColorText = colorToHex(workColor)
-	}
-	if setting.NoteColorText == "" {
-		setting.NoteColorText = colorToHex(noteColor)
-	}
-	if setting.BgColorText == "" {
-		setting.BgColorText = colorToHex(bgColor)
+	if err := json.Unmarshal(content, &setting); err != nil {
+		logError("解析设置失败:", err)
 	}
 
-}
+	normalizeSettings()
 
-func saveSettings() {
-	go func() {
-		jsonData, err := json.MarshalIndent(setting, "", "  ")
-		if err != nil {
-			logError("编码设置失败:", err)
-			return
-		}
-
-		if err := os.WriteFile("settings.json", jsonData, 0644); err != nil {
-			logError("保存设置失败:", err)
+	// 旧格式转换后立即按新格式保存，原文件留作备份
+	if migrated {
+		logInfo("settings migrated to version %d", settingsVersion)
+		if err := writeFileAtomic(settingsFile+".bak", data, 0644); err != nil {
+			logError("备份旧设置失败:", err)
 		}
-	}()
+		saveSettings()
+	}
 }
 
 func updateTimeColor() {
-	if currentState == stateWorking || currentState == stateIdle || (currentState == statePause && nextState == stateWorking) {
-		if timeText.Color != workColor {
-			timeText.Color = workColor
-			timeText.Refresh()
-		}
-	} else {
-		if timeText.Color != breakColor {
-			timeText.Color = breakColor
-			timeText.Refresh()
-		}
+	if overtime {
+		return
+	}
+	if c := sessionColor(); timeText.Color != c {
+		timeText.Color = c
+		timeText.Refresh()
 	}
 }
 
 func createSettingsContent() fyne.CanvasObject {
-	var formItems []*widget.FormItem
+	// 每个分页一组表单项
+	var timerItems, appearanceItems, soundItems, dataItems, integrationItems, shortcutItems []*widget.FormItem
+	settingsChecks = nil
 
 	// 工作时间设置
-	workEntry := newFixedWidthEntry(100, 36)
-	workEntry.Objects[0].(*widget.Entry).SetText(strconv.Itoa(setting.WorkTime))
-	workEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
-		if val, err := strconv.Atoi(text); err == nil {
-			setting.WorkTime = val
-		}
-		if currentState == stateIdle {
-			resetTimer()
-		}
-	}
-	workContainer := container.NewHBox(workEntry, widget.NewLabel("分钟"))
-	formItems = append(formItems, widget.NewFormItem("番茄时钟:", workContainer))
+	workContainer := newIntField(tr("settings.workTime"), 100, setting.Timer.WorkTime, workTimeRange, func(val int) {
+		setting.Timer.WorkTime = val
+		clearPresetIfChanged()
+	}, widget.NewLabel(tr("unit.minutes")))
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.workTime"), workContainer))
 
 	// 休息时间设置
-	breakEntry := newFixedWidthEntry(100, 36)
-	breakEntry.Objects[0].(*widget.Entry).SetText(strconv.Itoa(setting.BreakTime))
-	breakEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
-		if val, err := strconv.Atoi(text); err == nil {
-			setting.BreakTime = val
-		}
-	}
-	breakContainer := container.NewHBox(breakEntry, widget.NewLabel("分钟"))
-	formItems = append(formItems, widget.NewFormItem("休息时钟:", breakContainer))
-
-	//背景色设置
-	bgColorEntry := newFixedWidthEntry(100, 36)
-	bgColorEntry.Objects[0].(*widget.Entry).SetText(setting.BgColorText)
-	bgColorEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
-		if toColor, err := hexToColor(text); err == nil {
-			bgColor = toColor
-			setting.BgColorText = text
-			overlay.FillColor = toColor
-			overlay.Refresh()
-		}
+	breakContainer := newIntField(tr("settings.breakTime"), 100, setting.Timer.BreakTime, breakTimeRange, func(val int) {
+		setting.Timer.BreakTime = val
+		clearPresetIfChanged()
+	}, widget.NewLabel(tr("unit.minutes")))
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.breakTime"), breakContainer))
+
+	// 预设设置
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.presets"), createPresetSettings()))
+
+	// 计时方案设置
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.plan"), createPlanSettings()))
+
+	// 主题设置
+	appearanceItems = append(appearanceItems, widget.NewFormItem(tr("settings.theme"), createThemeSettings(refreshSettingsContent)))
+
+	// 颜色设置，修改后不再属于任何主题
+	colorRows := []struct {
+		label    string
+		text     *string
+		target   *color.Color
+		fallback color.Color
+	}{
+		{tr("settings.bgColor"), &setting.Appearance.BgColorText, &bgColor, defaultBgColor},
+		{tr("settings.workColor"), &setting.Appearance.WorkColorText, &workColor, defaultWorkColor},
+		{tr("settings.breakColor"), &setting.Appearance.BreakColorText, &breakColor, defaultBreakColor},
+		{tr("settings.noteColor"), &setting.Appearance.NoteColorText, &noteColor, defaultNoteColor},
+		{tr("settings.statColor"), &setting.Appearance.StatColorText, &statColor, defaultStatColor},
+	}
+	for _, row := range colorRows {
+		row := row
+		field := newColorField(strings.TrimSuffix(row.label, ":"), *row.text, func(c color.Color, hex string) {
+			*row.target = c
+			*row.text = hex
+			markCustomTheme()
+		})
+		resetBtn := widget.NewButton(tr("button.reset"), func() {
+			field.SetHex(colorToHex(row.fallback))
+		})
+		appearanceItems = append(appearanceItems, widget.NewFormItem(row.label, container.NewHBox(field.Object(), layout.NewSpacer(), resetBtn)))
 	}
-	resetBgColorBtn := widget.NewButton("重置", func() {
-		bgColor = defaultBgColor
-		setting.BgColorText = colorToHex(defaultBgColor)
-		bgColorEntry.Objects[0].(*widget.Entry).SetText(setting.BgColorText)
-		overlay.FillColor = defaultBgColor
-		overlay.Refresh()
+
+	// 进度环设置
+	appearanceItems = append(appearanceItems, widget.NewFormItem(tr("settings.ring"), createRingSettings()))
+
+	// 窗口标题和图标设置
+	appearanceItems = append(appearanceItems, widget.NewFormItem(tr("settings.status"), createStatusSettings()))
+
+	// 布局和缩放设置
+	appearanceItems = append(appearanceItems, widget.NewFormItem(tr("settings.layout"), createLayoutSettings()))
+
+	// 背景图片设置
+	appearanceItems = append(appearanceItems, createBackgroundSettings()...)
+
+	// 通知铃声设置
+	setting.workPathText = widget.NewLabel(tr("settings.notSet"))
+	if setting.Sounds.WorkInformPath != "" {
+		setting.workPathText.SetText(truncatePath(setting.Sounds.WorkInformPath, 50))
+	}
+	selectWorkInformBtn := widget.NewButton(tr("button.change"), selectWorkFile)
+	clearWorkInformBtn := widget.NewButton(tr("button.clear"), func() {
+		setting.Sounds.WorkInformPath = ""
+		setting.workPathText.SetText(tr("settings.notSet"))
 	})
-	resetBgColorContainer := container.NewHBox(
-		bgColorEntry,
+	workSoundContainer := container.NewHBox(
+		setting.workPathText,
 		layout.NewSpacer(),
-		resetBgColorBtn,
+		selectWorkInformBtn,
+		clearWorkInformBtn,
 	)
-	formItems = append(formItems, widget.NewFormItem("背景底色:", resetBgColorContainer))
-
-	// 番茄钟颜色设置
-	workColorEntry := newFixedWidthEntry(100, 36)
-	workColorEntry.Objects[0].(*widget.Entry).SetText(setting.WorkColorText)
-	workColorEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
-		if toColor, err := hexToColor(text); err == nil {
-			workColor = toColor
-			setting.WorkColorText = text
-			timeText.Color = workColor
-			timeText.Refresh()
-		}
-	}
-	resetWorkColorBtn := widget.NewButton("重置", func() {
-		workColor = defaultWorkColor
-		setting.WorkColorText = colorToHex(defaultWorkColor)
-		timeText.Color = workColor
-		timeText.Refresh()
-		workColorEntry.Objects[0].(*widget.Entry).SetText(setting.WorkColorText)
+	soundItems = append(soundItems, widget.NewFormItem(tr("settings.informSound"), workSoundContainer))
+
+	// 提前提醒设置
+	warnContainer := newCheckedEntry(tr("settings.warnOffsets"), 100, joinOffsets(setting.Sounds.WarnOffsets), validateOffsets, func(text string) {
+		setting.Sounds.WarnOffsets, _ = parseOffsets(text)
+	}, widget.NewLabel(tr("settings.warnOffsetsHint")))
+	soundItems = append(soundItems, widget.NewFormItem(tr("settings.warnOffsets"), warnContainer))
+
+	// 结束前闪烁设置
+	flashContainer := newIntField(tr("settings.flash"), 100, setting.Sounds.FlashSeconds, flashRange, func(val int) {
+		setting.Sounds.FlashSeconds = val
+	}, widget.NewLabel(tr("settings.flashHint")))
+	soundItems = append(soundItems, widget.NewFormItem(tr("settings.flash"), flashContainer))
+
+	// 延长/缩短步长设置
+	adjustContainer := newIntField(tr("settings.adjustStep"), 100, setting.Timer.AdjustMinutes, adjustRange, func(val int) {
+		setting.Timer.AdjustMinutes = val
+	}, widget.NewLabel(tr("unit.minutes")))
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.adjustStep"), adjustContainer))
+
+	// 心流模式设置
+	flowCheck := widget.NewCheck(tr("settings.flowCheck"), func(checked bool) {
+		setting.Timer.FlowMode = checked
 	})
-	resetWorkColorContainer := container.NewHBox(
-		workColorEntry,
-		layout.NewSpacer(),
-		resetWorkColorBtn,
-	)
-	formItems = append(formItems, widget.NewFormItem("番茄钟色:", resetWorkColorContainer))
-
-	// 休息钟颜色设置
-	breakColorEntry := newFixedWidthEntry(100, 36)
-	breakColorEntry.Objects[0].(*widget.Entry).SetText(setting.BreakColorText)
-	breakColorEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
-		if toColor, err := hexToColor(text); err == nil {
-			breakColor = toColor
-			setting.BreakColorText = text
-			timeText.Color = breakColor
-			timeText.Refresh()
-		}
-	}
-	resetBreakColorBtn := widget.NewButton("重置", func() {
-		breakColor = defaultBreakColor
-		setting.BreakColorText = colorToHex(defaultBreakColor)
-		timeText.Color = breakColor
-		timeText.Refresh()
-		breakColorEntry.Objects[0].(*widget.Entry).SetText(setting.BreakColorText)
+	flowCheck.SetChecked(setting.Timer.FlowMode)
+	scaleBreakCheck := widget.NewCheck(tr("settings.scaleBreak"), func(checked bool) {
+		setting.Timer.ScaleBreak = checked
 	})
-	resetBreakColorContainer := container.NewHBox(
-		breakColorEntry,
-		layout.NewSpacer(),
-		resetBreakColorBtn,
-	)
-	formItems = append(formItems, widget.NewFormItem("休息钟色:", resetBreakColorContainer))
-
-	// 状态文字颜色设置
-	NoteColorEntry := newFixedWidthEntry(100, 36)
-	NoteColorEntry.Objects[0].(*widget.Entry).SetText(setting.NoteColorText)
-	NoteColorEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
-		if toColor, err := hexToColor(text); err == nil {
-			setting.NoteColorText = text
-			noteColor = toColor
-			stateText.Color = noteColor
-			stateText.Refresh()
-		}
-	}
-	resetNoteColorBtn := widget.NewButton("重置", func() {
-		noteColor = defaultNoteColor
-		setting.NoteColorText = colorToHex(defaultStatColor)
-		stateText.Color = noteColor
-		stateText.Refresh()
-		NoteColorEntry.Objects[0].(*widget.Entry).SetText(setting.NoteColorText)
+	scaleBreakCheck.SetChecked(setting.Timer.ScaleBreak)
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.flow"), container.NewVBox(flowCheck, scaleBreakCheck)))
+
+	// 自动开始设置
+	autoBreakCheck := widget.NewCheck(tr("settings.autoBreak"), func(checked bool) {
+		setting.Timer.AutoStartBreak = checked
 	})
-	resetNoteColorContainer := container.NewHBox(
-		NoteColorEntry,
-		layout.NewSpacer(),
-		resetNoteColorBtn,
-	)
-	formItems = append(formItems, widget.NewFormItem("状态字色:", resetNoteColorContainer))
-
-	// 统计文字颜色设置
-	statColorEntry := newFixedWidthEntry(100, 36)
-	statColorEntry.Objects[0].(*widget.Entry).SetText(setting.StatColorText)
-	statColorEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
-		if toColor, err := hexToColor(text); err == nil {
-			setting.StatColorText = text
-			statColor = toColor
-			statTimeText.Color = statColor
-			statCountText.Color = statColor
-			statTimeText.Refresh()
-			statCountText.Refresh()
-		}
-	}
-	resetStatColorBtn := widget.NewButton("重置", func() {
-		statColor = defaultStatColor
-		setting.StatColorText = colorToHex(defaultStatColor)
-		statTimeText.Color = statColor
-		statCountText.Color = statColor
-		statColorEntry.Objects[0].(*widget.Entry).SetText(setting.StatColorText)
-		statTimeText.Refresh()
-		statCountText.Refresh()
+	autoBreakCheck.SetChecked(setting.Timer.AutoStartBreak)
+	autoWorkCheck := widget.NewCheck(tr("settings.autoWork"), func(checked bool) {
+		setting.Timer.AutoStartWork = checked
+	})
+	autoWorkCheck.SetChecked(setting.Timer.AutoStartWork)
+	autoDelayField := newIntField(tr("settings.autoStart"), 60, setting.Timer.AutoStartDelay, autoDelayRange, func(val int) {
+		setting.Timer.AutoStartDelay = val
+	}, widget.NewLabel(tr("settings.autoDelayHint")))
+	autoStartContainer := container.NewHBox(autoBreakCheck, autoWorkCheck, autoDelayField)
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.autoStart"), autoStartContainer))
+
+	launchCheck := widget.NewCheck(tr("settings.autoLaunch"), func(checked bool) {
+		setting.Timer.AutoStartOnLaunch = checked
+	})
+	launchCheck.SetChecked(setting.Timer.AutoStartOnLaunch)
+	hoursStartField := newCheckedEntry(tr("settings.workHours"), 70, setting.Timer.WorkHoursStart, validateClock, func(text string) {
+		setting.Timer.WorkHoursStart = text
+	}, widget.NewLabel(tr("settings.hoursTo")))
+	hoursEndField := newCheckedEntry(tr("settings.workHours"), 70, setting.Timer.WorkHoursEnd, validateClock, func(text string) {
+		setting.Timer.WorkHoursEnd = text
+	})
+	launchContainer := container.NewHBox(launchCheck, hoursStartField, hoursEndField)
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.workHours"), launchContainer))
+	dataItems = append(dataItems, widget.NewFormItem(tr("settings.sleep"), createSleepPolicySelect()))
+	dataItems = append(dataItems, createDataLocationItems()...)
+	integrationItems = append(integrationItems, widget.NewFormItem(tr("settings.idle"), createIdleSettings()))
+	integrationItems = append(integrationItems, widget.NewFormItem(tr("settings.lock"), createLockSettings()))
+
+	// 提醒铃声设置
+	setting.warnPathText = widget.NewLabel(tr("settings.sameAsInform"))
+	if setting.Sounds.WarnInformPath != "" {
+		setting.warnPathText.SetText(truncatePath(setting.Sounds.WarnInformPath, 50))
+	}
+	selectWarnInformBtn := widget.NewButton(tr("button.change"), selectWarnFile)
+	clearWarnInformBtn := widget.NewButton(tr("button.clear"), func() {
+		setting.Sounds.WarnInformPath = ""
+		setting.warnPathText.SetText(tr("settings.sameAsInform"))
 	})
-	resetStatColorContainer := container.NewHBox(
-		statColorEntry,
+	warnSoundContainer := container.NewHBox(
+		setting.warnPathText,
 		layout.NewSpacer(),
-		resetStatColorBtn,
+		selectWarnInformBtn,
+		clearWarnInformBtn,
 	)
-	formItems = append(formItems, widget.NewFormItem("统计字色:", resetStatColorContainer))
+	soundItems = append(soundItems, widget.NewFormItem(tr("settings.warnSound"), warnSoundContainer))
 
-	// 通知铃声设置
-	setting.workPathText = widget.NewLabel("未设置")
-	if setting.WorkInformPath != "" {
-		setting.workPathText.SetText(truncatePath(setting.WorkInformPath, 50))
-	}
-	selectWorkInformBtn := widget.NewButton("更改", selectWorkFile)
-	workSoundContainer := container.NewHBox(
-		setting.workPathText,
-		layout.NewSpacer(),
-		selectWorkInformBtn,
-	)
-	formItems = append(formItems, widget.NewFormItem("通知铃声:", workSoundContainer))
+	// 关闭窗口行为设置
+	trayCheck := widget.NewCheck(tr("settings.trayCheck"), func(checked bool) {
+		setting.Integrations.MinimizeToTray = checked
+	})
+	trayCheck.SetChecked(setting.Integrations.MinimizeToTray)
+	integrationItems = append(integrationItems, widget.NewFormItem(tr("settings.tray"), trayCheck))
 
-	// 创建表单
-	form := widget.NewForm(formItems...)
+	// 严格休息设置
+	strictCheck := widget.NewCheck(tr("settings.strictCheck"), func(checked bool) {
+		setting.Timer.StrictBreak = checked
+	})
+	strictCheck.SetChecked(setting.Timer.StrictBreak)
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.strict"), container.NewHBox(strictCheck, createMonitorCheck())))
+
+	skipDelayField := newIntField(tr("settings.skipLimit"), 100, setting.Timer.StrictSkipDelay, skipDelayRange, func(val int) {
+		setting.Timer.StrictSkipDelay = val
+	}, widget.NewLabel(tr("settings.skipDelayHint")))
+	skipConfirmCheck := widget.NewCheck(tr("settings.skipConfirm"), func(checked bool) {
+		setting.Timer.StrictSkipConfirm = checked
+	})
+	skipConfirmCheck.SetChecked(setting.Timer.StrictSkipConfirm)
+	skipDelayContainer := container.NewHBox(skipDelayField, skipConfirmCheck)
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.skipLimit"), skipDelayContainer))
+
+	skipsContainer := newIntField(tr("settings.dailySkips"), 100, setting.Timer.StrictSkipsPerDay, dailySkipsRange, func(val int) {
+		setting.Timer.StrictSkipsPerDay = val
+	}, widget.NewLabel(tr("settings.skipsHint")))
+	timerItems = append(timerItems, widget.NewFormItem(tr("settings.dailySkips"), skipsContainer))
+
+	// 快捷键设置
+	for _, item := range shortcutActions {
+		action := item.name
+		shortcutEntry := newFixedWidthEntry(100, 36)
+		shortcutEntry.Objects[0].(*widget.Entry).SetText(setting.Shortcuts.Bindings[action])
+		shortcutEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
+			setting.Shortcuts.Bindings[action] = text
+			applyAllShortcuts()
+		}
+		shortcutItems = append(shortcutItems, widget.NewFormItem(tr(item.labelKey)+":", shortcutEntry))
+	}
+
+	globalHotkeyEntry := newFixedWidthEntry(140, 36)
+	globalHotkeyEntry.Objects[0].(*widget.Entry).SetText(setting.Shortcuts.GlobalHotkey)
+	globalHotkeyEntry.Objects[0].(*widget.Entry).SetPlaceHolder("Ctrl+Alt+P")
+	globalHotkeyEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
+		setting.Shortcuts.GlobalHotkey = text
+	}
+	globalHotkeyEntry.Objects[0].(*widget.Entry).OnSubmitted = func(text string) {
+		registerGlobalHotkey(text)
+	}
+	globalHotkeyContainer := container.NewHBox(globalHotkeyEntry, widget.NewLabel(tr("settings.hotkeyHint")))
+	shortcutItems = append(shortcutItems, widget.NewFormItem(tr("settings.globalHotkey"), globalHotkeyContainer))
+	appearanceItems = append(appearanceItems, widget.NewFormItem(tr("settings.language"), createLanguageSelect()))
+
+	// 按分页创建表单，重建内容时保持当前分页
+	tabs := container.NewAppTabs(
+		container.NewTabItem(tr("settings.tab.timer"), settingsPage(timerItems)),
+		container.NewTabItem(tr("settings.tab.appearance"), settingsPage(appearanceItems)),
+		container.NewTabItem(tr("settings.tab.sounds"), settingsPage(soundItems)),
+		container.NewTabItem(tr("settings.tab.data"), settingsPage(dataItems)),
+		container.NewTabItem(tr("settings.tab.integrations"), settingsPage(integrationItems)),
+		container.NewTabItem(tr("settings.tab.shortcuts"), settingsPage(shortcutItems)),
+	)
+	tabs.SelectIndex(settingsTab)
+	tabs.OnSelected = func(*container.TabItem) {
+		settingsTab = tabs.SelectedIndex()
+	}
 
-	// 创建按钮区域
-	saveButton := widget.NewButton("关闭", func() {
+	// 创建按钮区域，修改先实时预览，应用后才保存，取消则恢复打开时的设置
+	applyButton := widget.NewButton(tr("settings.apply"), applySettings)
+	applyButton.Importance = widget.HighImportance
+	cancelButton := widget.NewButton(tr("button.cancel"), func() {
 		closeSettingsWindow()
 	})
+	restoreButton := widget.NewButton(tr("settings.restore"), confirmRestoreDefaults)
 
 	buttonArea := container.NewHBox(
-		saveButton,
+		restoreButton,
+		cancelButton,
+		applyButton,
 	)
 
-	// 添加垂直间距和按钮区域
-	return container.NewVBox(
-		form,
+	// 按钮固定在底部，不随分页滚动
+	return container.NewBorder(nil,
 		container.NewCenter(
 			container.NewPadded(buttonArea),
 		),
-	)
+		nil, nil, tabs)
 }
 
 func closeSettingsWindow() {
@@ -895,21 +1262,29 @@ func closeSettingsWindow() {
 	windowClosing = true
 	defer func() { windowClosing = false }()
 
-	saveSettings()
+	discardSettingsChanges()
 	if settingsWindow != nil {
 		settingsWindow.Close()
 		settingsWindow = nil
 	}
 	updateTimeColor()
+	registerGlobalHotkey(setting.Shortcuts.GlobalHotkey)
 }
 
 func selectWorkFile() {
 	selectFile(func(filePath string) {
-		setting.WorkInformPath = filePath
+		setting.Sounds.WorkInformPath = filePath
 		setting.workPathText.SetText(truncatePath(filePath, 30))
 	}, "mp3")
 }
 
+func selectWarnFile() {
+	selectFile(func(filePath string) {
+		setting.Sounds.WarnInformPath = filePath
+		setting.warnPathText.SetText(truncatePath(filePath, 30))
+	}, "mp3")
+}
+
 func selectFile(callback func(string), fType string) {
 	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
 		if err != nil || reader == nil {
@@ -925,12 +1300,12 @@ func selectFile(callback func(string), fType string) {
 		filePath := reader.URI().Path()
 		if fType == "img" {
 			if !isImgFile(filePath) {
-				dialog.ShowInformation("提示", "请选择正确的图片文件)", window)
+				dialog.ShowInformation(tr("dialog.hint"), tr("file.invalidImage"), window)
 				return
 			}
 		} else {
 			if !isAudioFile(filePath) {
-				dialog.ShowInformation("提示", "请选择MP3音频文件)", window)
+				dialog.ShowInformation(tr("dialog.hint"), tr("file.invalidAudio"), window)
 				return
 			}
 		}
@@ -938,6 +1313,31 @@ func selectFile(callback func(string), fType string) {
 	}, window)
 }
 
+// parseOffsets 解析逗号分隔的秒数，如 "120,30"
+func parseOffsets(text string) ([]int, error) {
+	var offsets []int
+	for _, part := range strings.Split(text, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		val, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, val)
+	}
+	return offsets, nil
+}
+
+func joinOffsets(offsets []int) string {
+	parts := make([]string, 0, len(offsets))
+	for _, offset := range offsets {
+		parts = append(parts, strconv.Itoa(offset))
+	}
+	return strings.Join(parts, ",")
+}
+
 func truncatePath(path string, maxLen int) string {
 	if len(path) <= maxLen {
 		return path
@@ -1015,19 +1415,73 @@ func logInfo(message string, args ...interface{}) {
 
 func initDatabase() error {
 	var err error
-	db, err = sql.Open("sqlite3", "./pomodoro.db")
+	db, err = sql.Open("sqlite3", "./"+databaseFile)
 	if err != nil {
 		logError("open db error", err)
-		return fmt.Errorf("打开数据库失败: %w", err)
+		return fmt.Errorf("%s: %w", tr("db.openFailed"), err)
 	}
 
 	if _, err := db.Exec(CREATE_SQL); err != nil {
 		logError("create db table error", err)
-		return fmt.Errorf("创建表失败: %w", err)
+		return fmt.Errorf("%s: %w", tr("db.createFailed"), err)
+	}
+
+	// 旧数据库没有调整和跳过字段，补上
+	if err := ensureColumn("task_record", "adjust", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		logError("add adjust column error", err)
+		return fmt.Errorf("%s: %w", tr("db.upgradeFailed"), err)
+	}
+	if err := ensureColumn("task_record", "skipped", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		logError("add skipped column error", err)
+		return fmt.Errorf("%s: %w", tr("db.upgradeFailed"), err)
+	}
+	// 休眠时长，单位秒
+	if err := ensureColumn("task_record", "sleep_gap", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		logError("add sleep_gap column error", err)
+		return fmt.Errorf("%s: %w", tr("db.upgradeFailed"), err)
+	}
+
+	if _, err := db.Exec(CREATE_SKIP_SQL); err != nil {
+		logError("create skip table error", err)
+		return fmt.Errorf("%s: %w", tr("db.createFailed"), err)
+	}
+
+	if _, err := db.Exec(CREATE_ACTIVE_SQL); err != nil {
+		logError("create active session table error", err)
+		return fmt.Errorf("%s: %w", tr("db.createFailed"), err)
 	}
 	return nil
 }
 
+// ensureColumn 表中缺少字段时追加该字段
+func ensureColumn(table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, colType    string
+			defaultValue     sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 func addTimeRecord(record taskRecord) error {
 	_, err := db.Exec(
 		INSERT_SQL,
@@ -1036,16 +1490,57 @@ func addTimeRecord(record taskRecord) error {
 		record.EndTime.Format(time.DateTime),
 		record.Duration,
 		record.Type,
+		record.Adjust,
+		record.Skipped,
+		record.SleepGap,
 	)
 	return err
 }
 
+func addBreakSkip(skipTime time.Time) error {
+	_, err := db.Exec(INSERT_SKIP_SQL, skipTime.Format("2006-01-02"), skipTime.Format(time.DateTime))
+	return err
+}
+
+func countBreakSkipsByDate(date string) (int, error) {
+	var total int
+	err := db.QueryRow(COUNT_SKIP_SQL, date).Scan(&total)
+	return total, err
+}
+
 func countRecordByDate(date string) (int, error) {
 	var total int
 	err := db.QueryRow(COUNT_SQL, date).Scan(&total)
 	return total, err
 }
 
+// typeStat 某一计时类型当天的次数和总时长
+type typeStat struct {
+	Type     string
+	Count    int
+	Duration int
+}
+
+func getTypeStatsByDate(date string) ([]typeStat, error) {
+	rows, err := db.Query(TYPE_SQL, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []typeStat
+	for rows.Next() {
+		var stat typeStat
+		var duration sql.NullInt64
+		if err := rows.Scan(&stat.Type, &stat.Count, &duration); err != nil {
+			return nil, err
+		}
+		stat.Duration = int(duration.Int64)
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
+
 func getTotalWorkTimeByDate(date string) (int, error) {
 	var total int
 	err := db.QueryRow(DURATION_SQL, date).Scan(&total)
@@ -1060,6 +1555,15 @@ func playSound(filePath string) {
 }
 
 func playSoundWithBeep(filePath string) {
+	playSoundWithVolume(filePath, 0)
+}
+
+// playSoundWithVolume 按指定音量播放，volume 以2为底，0为原始音量，负数降低音量
+func playSoundWithVolume(filePath string, volume float64) {
+	if filePath == "" {
+		return
+	}
+
 	// 打开文件
 	f, err := os.Open(filePath)
 	if err != nil {
@@ -1088,7 +1592,11 @@ func playSoundWithBeep(filePath string) {
 	done := make(chan bool)
 
 	// 播放音频并在完成后关闭资源
-	speaker.Play(beep.Seq(streamer, beep.Callback(func() {
+	var source beep.Streamer = streamer
+	if volume != 0 {
+		source = &effects.Volume{Streamer: streamer, Base: 2, Volume: volume}
+	}
+	speaker.Play(beep.Seq(source, beep.Callback(func() {
 		// 音频播放完成后关闭流和文件
 		streamer.Close()
 		f.Close()
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	defaultMiniWidth  = 160
+	defaultMiniHeight = 64
+)
+
+var (
+	miniWindow   fyne.Window
+	miniTimeText *canvas.Text
+	miniBar      *progressLine
+	miniRing     *progressRing
+)
+
+// toggleMiniMode 在主窗口和迷你窗口之间切换
+func toggleMiniMode() {
+	if miniWindow != nil {
+		closeMiniWindow()
+		return
+	}
+	showMiniWindow()
+}
+
+func showMiniWindow() {
+	// 只有能移动窗口时才去掉边框，否则无边框窗口无法拖动
+	if drv, ok := myApp.Driver().(desktop.Driver); ok && nativeWindowControl {
+		miniWindow = drv.CreateSplashWindow()
+	} else {
+		miniWindow = myApp.NewWindow(appTitle)
+	}
+	miniWindow.SetPadded(false)
+	miniWindow.SetCloseIntercept(closeMiniWindow)
+
+	miniTimeText = canvas.NewText(displayTime(), timeText.Color)
+	miniTimeText.TextSize = 36
+	miniTimeText.Alignment = fyne.TextAlignCenter
+
+	// 开启进度环时在时间左侧显示小环，否则在底部显示进度条
+	var body fyne.CanvasObject
+	if setting.Appearance.RingMode != ringOff {
+		miniRing = newProgressRing(timeText.Color, fyne.NewSize(40, 40))
+		miniRing.value = sessionRemaining()
+		body = container.NewBorder(nil, nil, container.NewPadded(miniRing), nil, container.NewCenter(miniTimeText))
+	} else {
+		miniBar = newProgressLine(timeText.Color)
+		miniBar.SetValue(sessionProgress())
+		body = container.NewBorder(nil, miniBar, nil, nil, container.NewCenter(miniTimeText))
+	}
+
+	surface := newMiniSurface(container.NewStack(
+		canvas.NewRectangle(bgColor),
+		body,
+	))
+	miniWindow.SetContent(surface)
+	applyShortcuts(miniWindow.Canvas())
+
+	width, height := setting.Window.MiniWidth, setting.Window.MiniHeight
+	if width <= 0 || height <= 0 {
+		width, height = defaultMiniWidth, defaultMiniHeight
+	}
+	miniWindow.Resize(fyne.NewSize(width, height))
+
+	window.Hide()
+	miniWindow.Show()
+	setAlwaysOnTop(miniWindow, true)
+	if setting.Window.MiniX != 0 || setting.Window.MiniY != 0 {
+		moveWindow(miniWindow, setting.Window.MiniX, setting.Window.MiniY)
+	}
+}
+
+func closeMiniWindow() {
+	if miniWindow == nil {
+		return
+	}
+	setting.Window.MiniWidth = miniWindow.Canvas().Size().Width
+	setting.Window.MiniHeight = miniWindow.Canvas().Size().Height
+	if x, y, ok := windowPosition(miniWindow); ok {
+		setting.Window.MiniX, setting.Window.MiniY = x, y
+	}
+	saveSettings()
+
+	delete(registeredShortcuts, miniWindow.Canvas())
+	miniWindow.Close()
+	miniWindow = nil
+	miniTimeText = nil
+	miniBar = nil
+	miniRing = nil
+
+	window.Show()
+	window.RequestFocus()
+}
+
+// updateMini 在计时刷新时同步迷你窗口的时间、颜色和进度
+func updateMini(text string) {
+	if miniWindow == nil {
+		return
+	}
+	miniTimeText.Text = text
+	miniTimeText.Color = timeText.Color
+	miniTimeText.Refresh()
+	if miniRing != nil {
+		miniRing.SetColor(timeText.Color)
+		miniRing.SetValue(sessionRemaining())
+	}
+	if miniBar != nil {
+		miniBar.SetColor(timeText.Color)
+		miniBar.SetValue(sessionProgress())
+	}
+}
+
+// sessionProgress 返回当前会话已完成的比例
+func sessionProgress() float64 {
+	if total <= 0 {
+		return 0
+	}
+	progress := 1 - float64(remaining)/float64(total)
+	if progress < 0 {
+		return 0
+	}
+	if progress > 1 {
+		return 1
+	}
+	return progress
+}
+
+// miniSurface 承载迷你窗口内容，双击返回主窗口，拖动移动无边框窗口
+type miniSurface struct {
+	widget.BaseWidget
+	content  fyne.CanvasObject
+	dragging bool
+	x, y     float32
+}
+
+func newMiniSurface(content fyne.CanvasObject) *miniSurface {
+	s := &miniSurface{content: content}
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+func (s *miniSurface) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(s.content)
+}
+
+func (s *miniSurface) Tapped(*fyne.PointEvent) {}
+
+func (s *miniSurface) DoubleTapped(*fyne.PointEvent) {
+	toggleMiniMode()
+}
+
+func (s *miniSurface) Dragged(ev *fyne.DragEvent) {
+	if miniWindow == nil {
+		return
+	}
+	if !s.dragging {
+		x, y, ok := windowPosition(miniWindow)
+		if !ok {
+			return
+		}
+		s.dragging = true
+		s.x, s.y = float32(x), float32(y)
+	}
+	scale := miniWindow.Canvas().Scale()
+	s.x += ev.Dragged.DX * scale
+	s.y += ev.Dragged.DY * scale
+	moveWindow(miniWindow, int(s.x), int(s.y))
+}
+
+func (s *miniSurface) DragEnd() {
+	s.dragging = false
+}
+
+// progressLine 细进度条，比 widget.ProgressBar 更适合迷你窗口
+type progressLine struct {
+	widget.BaseWidget
+	value float64
+	track *canvas.Rectangle
+	bar   *canvas.Rectangle
+}
+
+func newProgressLine(barColor color.Color) *progressLine {
+	p := &progressLine{
+		track: canvas.NewRectangle(color.NRGBA{R: 128, G: 128, B: 128, A: 60}),
+		bar:   canvas.NewRectangle(barColor),
+	}
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+func (p *progressLine) SetValue(value float64) {
+	p.value = value
+	p.Refresh()
+}
+
+func (p *progressLine) SetColor(c color.Color) {
+	p.bar.FillColor = c
+	p.Refresh()
+}
+
+func (p *progressLine) CreateRenderer() fyne.WidgetRenderer {
+	return &progressLineRenderer{line: p}
+}
+
+type progressLineRenderer struct {
+	line *progressLine
+}
+
+func (r *progressLineRenderer) Layout(size fyne.Size) {
+	r.line.track.Resize(size)
+	r.line.track.Move(fyne.NewPos(0, 0))
+	r.line.bar.Resize(fyne.NewSize(size.Width*float32(r.line.value), size.Height))
+	r.line.bar.Move(fyne.NewPos(0, 0))
+}
+
+func (r *progressLineRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(0, 4)
+}
+
+func (r *progressLineRenderer) Refresh() {
+	r.Layout(r.line.Size())
+	r.line.track.Refresh()
+	r.line.bar.Refresh()
+}
+
+func (r *progressLineRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.line.track, r.line.bar}
+}
+
+func (r *progressLineRenderer) Destroy() {}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// 计时模式，同时作为 task_record 的 type 值
+const (
+	modePomodoro  = "pomodoro"
+	modeStopwatch = "stopwatch"
+	modeCountdown = "countdown"
+)
+
+var modeOrder = []string{modePomodoro, modeStopwatch, modeCountdown}
+
+func modeLabel(mode string) string {
+	return tr("mode." + mode)
+}
+
+func timerMode() string {
+	switch setting.Timer.TimerMode {
+	case modeStopwatch, modeCountdown:
+		return setting.Timer.TimerMode
+	default:
+		return modePomodoro
+	}
+}
+
+// idleDuration 返回当前模式下准备开始时显示的时长
+func idleDuration() time.Duration {
+	switch timerMode() {
+	case modeStopwatch:
+		return 0
+	case modeCountdown:
+		return time.Duration(setting.Timer.CountdownMinutes) * time.Minute
+	default:
+		return phaseDuration(currentPhase())
+	}
+}
+
+// displayTime 返回界面上应显示的时间，正计时显示已用时间，其余显示剩余时间
+func displayTime() string {
+	if timerMode() == modeStopwatch {
+		return formatDuration(totalRunningTime)
+	}
+	return formatDuration(remaining)
+}
+
+// showModeDialog 选择计时模式，倒计时可以临时指定分钟数
+func showModeDialog() {
+	if currentState != stateIdle {
+		dialog.ShowInformation(tr("dialog.hint"), tr("mode.busy"), window)
+		return
+	}
+
+	labels := make([]string, 0, len(modeOrder))
+	for _, mode := range modeOrder {
+		labels = append(labels, modeLabel(mode))
+	}
+
+	minutesEntry := widget.NewEntry()
+	minutesEntry.SetText(strconv.Itoa(setting.Timer.CountdownMinutes))
+	minutesEntry.Validator = func(text string) error {
+		_, err := strconv.Atoi(text)
+		return err
+	}
+
+	modeRadio := widget.NewRadioGroup(labels, func(selected string) {
+		if selected == modeLabel(modeCountdown) {
+			minutesEntry.Enable()
+		} else {
+			minutesEntry.Disable()
+		}
+	})
+	modeRadio.Horizontal = true
+	modeRadio.SetSelected(modeLabel(timerMode()))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(tr("mode.mode"), modeRadio),
+		widget.NewFormItem(tr("mode.countdownMinutes"), minutesEntry),
+	}
+	modeDialog := dialog.NewForm(tr("mode.title"), tr("mode.switch"), tr("button.cancel"), items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		changeSettings(func(s *settings) {
+			for _, mode := range modeOrder {
+				if modeLabel(mode) == modeRadio.Selected {
+					s.Timer.TimerMode = mode
+				}
+			}
+			if val, err := strconv.Atoi(minutesEntry.Text); err == nil && val > 0 {
+				s.Timer.CountdownMinutes = val
+			}
+		})
+		resetTimer()
+	}, window)
+	modeDialog.Resize(fyne.NewSize(360, 200))
+	modeDialog.Show()
+}
@@ -0,0 +1,56 @@
+//go:build windows
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const monitorInfoPrimary = 0x1
+
+var (
+	procEnumDisplayMonitors = user32.NewProc("EnumDisplayMonitors")
+	procGetMonitorInfoW     = user32.NewProc("GetMonitorInfoW")
+
+	// 回调数量有上限，只创建一次，枚举结果放在 foundMonitors 中
+	enumMonitorCallback = syscall.NewCallback(collectMonitor)
+	foundMonitors       []monitorInfo
+)
+
+// monitorInfoEx 对应 MONITORINFOEXW
+type monitorInfoEx struct {
+	size    uint32
+	monitor windows.Rect
+	work    windows.Rect
+	flags   uint32
+	device  [32]uint16
+}
+
+// listMonitors 通过 EnumDisplayMonitors 列出当前连接的显示器
+func listMonitors() []monitorInfo {
+	foundMonitors = nil
+	procEnumDisplayMonitors.Call(0, 0, enumMonitorCallback, 0)
+	result := foundMonitors
+	foundMonitors = nil
+	return result
+}
+
+func collectMonitor(hmonitor, hdc, clip, data uintptr) uintptr {
+	info := monitorInfoEx{}
+	info.size = uint32(unsafe.Sizeof(info))
+	if ret, _, _ := procGetMonitorInfoW.Call(hmonitor, uintptr(unsafe.Pointer(&info))); ret == 0 {
+		return 1
+	}
+	foundMonitors = append(foundMonitors, monitorInfo{
+		Name:    windows.UTF16ToString(info.device[:]),
+		X:       int(info.monitor.Left),
+		Y:       int(info.monitor.Top),
+		Width:   int(info.monitor.Right - info.monitor.Left),
+		Height:  int(info.monitor.Bottom - info.monitor.Top),
+		Primary: info.flags&monitorInfoPrimary != 0,
+	})
+	return 1 // 继续枚举
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// skipConfirmText 跳过严格休息前需要输入的文字
+func skipConfirmText() string {
+	return tr("break.skipConfirmText")
+}
+
+var breakActivities = []string{
+	"break.activity.stretch",
+	"break.activity.eyes",
+	"break.activity.water",
+	"break.activity.breathe",
+	"break.activity.walk",
+	"break.activity.neck",
+}
+
+var (
+	breakWindow     fyne.Window
+	breakTimeText   *canvas.Text
+	breakSkipButton *widget.Button
+	breakSkipEntry  *widget.Entry
+	breakSkipReady  bool
+)
+
+// showBreakOverlay 严格休息模式下打开全屏休息窗口
+func showBreakOverlay() {
+	if !setting.Timer.StrictBreak || breakWindow != nil {
+		return
+	}
+
+	breakWindow = myApp.NewWindow(tr("break.title"))
+	breakWindow.SetCloseIntercept(func() {})
+
+	breakTimeText = canvas.NewText(formatDuration(remaining), breakColor)
+	breakTimeText.TextSize = 160
+	breakTimeText.Alignment = fyne.TextAlignCenter
+
+	activityText := canvas.NewText(tr(breakActivities[rand.Intn(len(breakActivities))]), noteColor)
+	activityText.TextSize = 32
+	activityText.Alignment = fyne.TextAlignCenter
+
+	breakSkipReady = false
+	breakSkipButton = widget.NewButton(tr("break.skip"), skipBreak)
+	breakSkipButton.Disable()
+
+	var skipArea fyne.CanvasObject = breakSkipButton
+	if setting.Timer.StrictSkipConfirm {
+		breakSkipEntry = widget.NewEntry()
+		breakSkipEntry.SetPlaceHolder(tr("break.skipPlaceholder", skipConfirmText()))
+		breakSkipEntry.OnChanged = func(string) {
+			refreshSkipButton()
+		}
+		skipArea = container.NewVBox(breakSkipEntry, breakSkipButton)
+	}
+
+	used, err := countBreakSkipsByDate(today)
+	if err != nil {
+		logError("count break skip error", err)
+	}
+	left := setting.Timer.StrictSkipsPerDay - used
+	skipHint := canvas.NewText(trn("break.skipsLeft", maxInt(left, 0), maxInt(left, 0)), noteColor)
+	skipHint.Alignment = fyne.TextAlignCenter
+
+	breakWindow.SetContent(container.NewStack(
+		canvas.NewRectangle(bgColor),
+		container.NewCenter(container.NewVBox(
+			breakTimeText,
+			activityText,
+			container.NewCenter(container.NewGridWrap(fyne.NewSize(280, skipArea.MinSize().Height), skipArea)),
+			skipHint,
+		)),
+	))
+	showBreakWindow()
+
+	if left <= 0 {
+		breakSkipButton.SetText(tr("break.noSkipsLeft"))
+		return
+	}
+
+	delay := time.Duration(setting.Timer.StrictSkipDelay) * time.Second
+	skipWindow := breakWindow
+	time.AfterFunc(delay, func() {
+		fyne.Do(func() {
+			if breakWindow != skipWindow {
+				return
+			}
+			breakSkipReady = true
+			refreshSkipButton()
+		})
+	})
+}
+
+func refreshSkipButton() {
+	if breakSkipButton == nil {
+		return
+	}
+	confirmed := breakSkipEntry == nil || strings.TrimSpace(breakSkipEntry.Text) == skipConfirmText()
+	if breakSkipReady && confirmed {
+		breakSkipButton.Enable()
+	} else {
+		breakSkipButton.Disable()
+	}
+}
+
+// updateBreakOverlay 在计时刷新时更新全屏窗口的倒计时
+func updateBreakOverlay(text string) {
+	if breakWindow == nil {
+		return
+	}
+	breakTimeText.Text = text
+	breakTimeText.Refresh()
+}
+
+func closeBreakOverlay() {
+	if breakWindow == nil {
+		return
+	}
+	breakWindow.Close()
+	breakWindow = nil
+	breakTimeText = nil
+	breakSkipButton = nil
+	breakSkipEntry = nil
+	if miniWindow == nil {
+		window.Show()
+		window.RequestFocus()
+	}
+}
+
+func skipBreak() {
+	endBreakBySkip(skipTimer)
+}
+
+// endBreakBySkip 记一次跳过，关闭休息窗口后执行 action
+func endBreakBySkip(action func()) {
+	if err := addBreakSkip(time.Now()); err != nil {
+		logError("insert break skip error", err)
+	}
+	closeBreakOverlay()
+	action()
+}
+
+// strictBreakLocked 严格休息窗口显示时不能暂停或调整时长，否则倒计时会停住或被缩短，
+// 只能用跳过按钮提前结束；返回 true 时把休息窗口提到前面
+func strictBreakLocked() bool {
+	if breakWindow == nil {
+		return false
+	}
+	breakWindow.RequestFocus()
+	return true
+}
+
+// guardStrictBreak 包装托盘、快捷键和工具栏的跳过、重置，严格休息中同样受等待、确认和每日次数的限制，
+// 还不能跳过时只把休息窗口提到前面
+func guardStrictBreak(action func()) func() {
+	return func() {
+		if breakWindow == nil {
+			action()
+			return
+		}
+		if breakSkipButton == nil || breakSkipButton.Disabled() {
+			breakWindow.RequestFocus()
+			return
+		}
+		endBreakBySkip(action)
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
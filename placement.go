@@ -0,0 +1,145 @@
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// monitorInfo 显示器在整个桌面中的位置和大小，单位为像素
+type monitorInfo struct {
+	Name    string
+	X, Y    int
+	Width   int
+	Height  int
+	Primary bool
+}
+
+func (m monitorInfo) contains(x, y int) bool {
+	return x >= m.X && x < m.X+m.Width && y >= m.Y && y < m.Y+m.Height
+}
+
+// monitorAt 返回包含该点的显示器
+func monitorAt(x, y int) (monitorInfo, bool) {
+	for _, m := range listMonitors() {
+		if m.contains(x, y) {
+			return m, true
+		}
+	}
+	return monitorInfo{}, false
+}
+
+func findMonitor(name string) (monitorInfo, bool) {
+	for _, m := range listMonitors() {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return monitorInfo{}, false
+}
+
+// saveWindowGeometry 记录主窗口的大小、位置和所在显示器，位置相对于显示器左上角
+func saveWindowGeometry() {
+	setting.Window.Width = window.Canvas().Size().Width
+	setting.Window.Height = window.Canvas().Size().Height
+
+	x, y, ok := windowPosition(window)
+	if !ok {
+		return
+	}
+	if m, found := monitorAt(x, y); found {
+		setting.Window.Monitor = m.Name
+		setting.Window.X, setting.Window.Y = x-m.X, y-m.Y
+		return
+	}
+	setting.Window.Monitor = ""
+	setting.Window.X, setting.Window.Y = x, y
+}
+
+// restoreWindowPosition 启动时把主窗口放回上次的位置，原来的显示器不在时居中到主显示器
+func restoreWindowPosition() {
+	if !nativeWindowControl {
+		return
+	}
+	if setting.Window.Monitor == "" {
+		if setting.Window.X != 0 || setting.Window.Y != 0 {
+			moveWindow(window, setting.Window.X, setting.Window.Y)
+		}
+		return
+	}
+
+	m, ok := findMonitor(setting.Window.Monitor)
+	if !ok {
+		logInfo("monitor %s not found, center the window", setting.Window.Monitor)
+		window.CenterOnScreen()
+		return
+	}
+	// 显示器分辨率可能变小了，保证窗口完整地留在显示器内
+	scale := window.Canvas().Scale()
+	width := int(setting.Window.Width * scale)
+	height := int(setting.Window.Height * scale)
+	x := clampInt(setting.Window.X, 0, m.Width-width)
+	y := clampInt(setting.Window.Y, 0, m.Height-height)
+	moveWindow(window, m.X+x, m.Y+y)
+}
+
+func clampInt(value, low, high int) int {
+	if value > high {
+		value = high
+	}
+	if value < low {
+		value = low
+	}
+	return value
+}
+
+// currentMonitor 返回主窗口（迷你模式下为迷你窗口）所在的显示器
+func currentMonitor() (monitorInfo, bool) {
+	w := window
+	if miniWindow != nil {
+		w = miniWindow
+	}
+	x, y, ok := windowPosition(w)
+	if !ok {
+		return monitorInfo{}, false
+	}
+	return monitorAt(x, y)
+}
+
+// showBreakWindow 全屏显示休息窗口，开启跟随时放在主窗口所在的显示器上
+func showBreakWindow() {
+	m, ok := currentMonitor()
+	if !setting.Timer.FollowMonitor || !ok {
+		breakWindow.SetFullScreen(true)
+		breakWindow.Show()
+		breakWindow.RequestFocus()
+		return
+	}
+
+	// 全屏会使用窗口当前所在的显示器，先显示并移过去，等窗口管理器处理完移动再全屏
+	breakWindow.Show()
+	moveWindow(breakWindow, m.X+m.Width/4, m.Y+m.Height/4)
+	shown := breakWindow
+	time.AfterFunc(200*time.Millisecond, func() {
+		fyne.Do(func() {
+			if breakWindow != shown {
+				return
+			}
+			breakWindow.SetFullScreen(true)
+			breakWindow.RequestFocus()
+		})
+	})
+}
+
+// createMonitorCheck 设置窗口中休息窗口跟随主窗口所在显示器的开关
+func createMonitorCheck() fyne.CanvasObject {
+	check := widget.NewCheck(tr("settings.followMonitor"), func(checked bool) {
+		setting.Timer.FollowMonitor = checked
+	})
+	check.SetChecked(setting.Timer.FollowMonitor)
+	if !nativeWindowControl {
+		check.Disable()
+	}
+	return check
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// classicPlanName 不使用自定义方案时的名称，即专注和休息交替
+func classicPlanName() string {
+	return tr("plan.classic")
+}
+
+// planPhase 方案中的一个阶段，Break 为 true 时按休息处理，不计入番茄
+type planPhase struct {
+	Label   string `json:"label"`
+	Minutes int    `json:"minutes"`
+	Color   string `json:"color"`
+	Sound   string `json:"sound"`
+	Break   bool   `json:"break"`
+}
+
+// timerPlan 按顺序循环执行的一组阶段，如 50/10/50/10/90/30
+type timerPlan struct {
+	Name   string      `json:"name"`
+	Phases []planPhase `json:"phases"`
+}
+
+// phaseIndex 当前或下一个要开始的阶段在方案中的位置
+var phaseIndex int
+
+func findPlan(name string) *timerPlan {
+	for i := range setting.Timer.Plans {
+		if setting.Timer.Plans[i].Name == name {
+			return &setting.Timer.Plans[i]
+		}
+	}
+	return nil
+}
+
+// activePhases 返回当前方案的阶段，未选择方案时由专注和休息时长组成经典方案
+func activePhases() []planPhase {
+	if plan := findPlan(setting.Timer.ActivePlan); plan != nil && len(plan.Phases) > 0 {
+		return plan.Phases
+	}
+	return []planPhase{
+		{Label: tr("phase.work"), Minutes: setting.Timer.WorkTime},
+		{Label: tr("phase.break"), Minutes: setting.Timer.BreakTime, Break: true},
+	}
+}
+
+func usingPlan() bool {
+	plan := findPlan(setting.Timer.ActivePlan)
+	return plan != nil && len(plan.Phases) > 0
+}
+
+func currentPhase() planPhase {
+	phases := activePhases()
+	return phases[phaseIndex%len(phases)]
+}
+
+func phaseState(phase planPhase) state {
+	if phase.Break {
+		return stateBreaking
+	}
+	return stateWorking
+}
+
+// phaseDuration 返回阶段时长，开启等比休息时休息阶段按上次实际专注时长换算，至少一分钟
+func phaseDuration(phase planPhase) time.Duration {
+	d := time.Duration(phase.Minutes) * time.Minute
+	if !phase.Break || !setting.Timer.ScaleBreak || lastWorkTime <= 0 || lastWorkPlanned <= 0 {
+		return d
+	}
+	ratio := float64(lastWorkTime) / float64(lastWorkPlanned)
+	scaled := time.Duration(float64(d) * ratio).Round(time.Minute)
+	if scaled < time.Minute {
+		return time.Minute
+	}
+	return scaled
+}
+
+// normalizePlans 去掉配置文件中时长不合法的阶段，没有剩余阶段的方案一并去掉，
+// 否则这样的阶段一开始就结束，开启自动开始时会不停地切换
+func normalizePlans() {
+	plans := setting.Timer.Plans[:0]
+	for _, plan := range setting.Timer.Plans {
+		phases := plan.Phases[:0]
+		for _, phase := range plan.Phases {
+			if phase.Minutes > 0 {
+				phases = append(phases, phase)
+			}
+		}
+		if len(phases) != len(plan.Phases) {
+			logInfo("drop %d invalid phases from plan %s", len(plan.Phases)-len(phases), plan.Name)
+		}
+		plan.Phases = phases
+		if len(phases) == 0 {
+			logInfo("drop plan %s without valid phases", plan.Name)
+			continue
+		}
+		plans = append(plans, plan)
+	}
+	setting.Timer.Plans = plans
+	if findPlan(setting.Timer.ActivePlan) == nil {
+		setting.Timer.ActivePlan = ""
+	}
+}
+
+func phaseColor(phase planPhase) color.Color {
+	if phase.Color != "" {
+		if c, err := hexToColor(phase.Color); err == nil {
+			return c
+		}
+	}
+	if phase.Break {
+		return breakColor
+	}
+	return workColor
+}
+
+// sessionColor 返回当前阶段倒计时应使用的颜色
+func sessionColor() color.Color {
+	if timerMode() != modePomodoro {
+		return workColor
+	}
+	return phaseColor(currentPhase())
+}
+
+// advancePhase 进入方案中的下一阶段
+func advancePhase() {
+	phaseIndex = (phaseIndex + 1) % len(activePhases())
+	nextState = phaseState(currentPhase())
+}
+
+func resetPhase() {
+	phaseIndex = 0
+	nextState = phaseState(currentPhase())
+}
+
+// phaseProgressText 显示当前阶段名称和序号，如 "专注 2/6"
+func phaseProgressText() string {
+	phases := activePhases()
+	return fmt.Sprintf("%s %d/%d", currentPhase().Label, phaseIndex%len(phases)+1, len(phases))
+}
+
+func planNames() []string {
+	names := []string{classicPlanName()}
+	for _, plan := range setting.Timer.Plans {
+		names = append(names, plan.Name)
+	}
+	return names
+}
+
+// createPlanSettings 设置窗口中的方案选择和编辑
+func createPlanSettings() fyne.CanvasObject {
+	planSelect := widget.NewSelect(planNames(), nil)
+	selected := setting.Timer.ActivePlan
+	if findPlan(selected) == nil {
+		selected = classicPlanName()
+	}
+	planSelect.SetSelected(selected)
+	planSelect.OnChanged = func(name string) {
+		if name == classicPlanName() {
+			name = ""
+		}
+		if name == setting.Timer.ActivePlan {
+			return
+		}
+		setting.Timer.ActivePlan = name
+		if currentState == stateIdle {
+			resetTimer()
+		}
+	}
+
+	refresh := func(selectName string) {
+		planSelect.Options = planNames()
+		planSelect.SetSelected(selectName)
+		planSelect.Refresh()
+	}
+
+	editBtn := widget.NewButton(tr("button.edit"), func() {
+		plan := findPlan(setting.Timer.ActivePlan)
+		if plan == nil {
+			dialog.ShowInformation(tr("dialog.hint"), tr("plan.classicHint"), settingsWindow)
+			return
+		}
+		showPlanEditor(plan, refresh)
+	})
+	newBtn := widget.NewButton(tr("button.new"), func() {
+		showPlanEditor(nil, refresh)
+	})
+	deleteBtn := widget.NewButton(tr("button.delete"), func() {
+		name := setting.Timer.ActivePlan
+		if findPlan(name) == nil {
+			return
+		}
+		dialog.ShowConfirm(tr("plan.deleteTitle"), tr("plan.deleteMessage", name), func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			for i := range setting.Timer.Plans {
+				if setting.Timer.Plans[i].Name == name {
+					setting.Timer.Plans = append(setting.Timer.Plans[:i], setting.Timer.Plans[i+1:]...)
+					break
+				}
+			}
+			refresh(classicPlanName())
+		}, settingsWindow)
+	})
+
+	return container.NewHBox(planSelect, layout.NewSpacer(), editBtn, newBtn, deleteBtn)
+}
+
+// showPlanEditor 编辑或新建方案，每行一个阶段
+func showPlanEditor(plan *timerPlan, onSaved func(string)) {
+	editing := timerPlan{Name: tr("plan.newName"), Phases: []planPhase{
+		{Label: tr("phase.work"), Minutes: 50},
+		{Label: tr("phase.break"), Minutes: 10, Break: true},
+	}}
+	if plan != nil {
+		editing.Name = plan.Name
+		editing.Phases = append([]planPhase(nil), plan.Phases...)
+	}
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(editing.Name)
+	rows := container.NewVBox()
+
+	var rebuild func()
+	rebuild = func() {
+		rows.RemoveAll()
+		for i := range editing.Phases {
+			rows.Add(createPhaseRow(&editing, i, rebuild))
+		}
+		rows.Add(widget.NewButtonWithIcon(tr("plan.addPhase"), theme.ContentAddIcon(), func() {
+			editing.Phases = append(editing.Phases, planPhase{Label: tr("phase.work"), Minutes: 25})
+			rebuild()
+		}))
+	}
+	rebuild()
+
+	content := container.NewBorder(
+		widget.NewForm(widget.NewFormItem(tr("plan.name"), nameEntry)), nil, nil, nil,
+		container.NewVScroll(rows),
+	)
+	editor := dialog.NewCustomConfirm(tr("plan.editTitle"), tr("button.save"), tr("button.cancel"), content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		name := strings.TrimSpace(nameEntry.Text)
+		if err := validatePlan(name, plan, editing.Phases); err != nil {
+			dialog.ShowError(err, settingsWindow)
+			return
+		}
+		editing.Name = name
+		if plan != nil {
+			*plan = editing
+		} else {
+			setting.Timer.Plans = append(setting.Timer.Plans, editing)
+		}
+		setting.Timer.ActivePlan = name
+		onSaved(name)
+		if currentState == stateIdle {
+			resetTimer()
+		}
+	}, settingsWindow)
+	editor.Resize(fyne.NewSize(760, 420))
+	editor.Show()
+}
+
+func createPhaseRow(editing *timerPlan, i int, rebuild func()) fyne.CanvasObject {
+	phase := &editing.Phases[i]
+
+	labelEntry := newFixedWidthEntry(90, 36)
+	labelEntry.Objects[0].(*widget.Entry).SetText(phase.Label)
+	labelEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
+		phase.Label = text
+	}
+
+	minutesEntry := newFixedWidthEntry(60, 36)
+	minutesEntry.Objects[0].(*widget.Entry).SetText(strconv.Itoa(phase.Minutes))
+	minutesEntry.Objects[0].(*widget.Entry).OnChanged = func(text string) {
+		if val, err := strconv.Atoi(text); err == nil {
+			phase.Minutes = val
+		}
+	}
+
+	colorField := newColorField(tr("plan.phaseColor", i+1), phase.Color, func(_ color.Color, hex string) {
+		phase.Color = hex
+	})
+	colorField.AllowEmpty(tr("plan.defaultColor"), func() color.Color {
+		return phaseColor(planPhase{Break: phase.Break})
+	})
+
+	breakCheck := widget.NewCheck(tr("phase.break"), func(checked bool) {
+		phase.Break = checked
+		colorField.ShowDefault()
+	})
+	breakCheck.SetChecked(phase.Break)
+
+	soundLabel := widget.NewLabel(tr("plan.defaultSound"))
+	if phase.Sound != "" {
+		soundLabel.SetText(truncatePath(phase.Sound, 12))
+	}
+	soundBtn := widget.NewButtonWithIcon("", theme.MediaMusicIcon(), func() {
+		selectFile(func(filePath string) {
+			phase.Sound = filePath
+			soundLabel.SetText(truncatePath(filePath, 12))
+		}, "mp3")
+	})
+
+	deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		editing.Phases = append(editing.Phases[:i], editing.Phases[i+1:]...)
+		rebuild()
+	})
+
+	return container.NewHBox(
+		widget.NewLabel(strconv.Itoa(i+1)+"."),
+		labelEntry,
+		minutesEntry,
+		widget.NewLabel(tr("unit.minutes")),
+		colorField.Object(),
+		breakCheck,
+		soundBtn,
+		soundLabel,
+		layout.NewSpacer(),
+		deleteBtn,
+	)
+}
+
+func validatePlan(name string, original *timerPlan, phases []planPhase) error {
+	if name == "" || name == classicPlanName() {
+		return errors.New(tr("plan.errName", classicPlanName()))
+	}
+	if existing := findPlan(name); existing != nil && existing != original {
+		return errors.New(tr("plan.errExists", name))
+	}
+	if len(phases) == 0 {
+		return errors.New(tr("plan.errEmpty"))
+	}
+	for i, phase := range phases {
+		if phase.Minutes <= 0 {
+			return errors.New(tr("plan.errMinutes", i+1))
+		}
+	}
+	return nil
+}
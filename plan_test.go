@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestPhaseDurationScaledBreak(t *testing.T) {
+	setting = defaultSettings()
+	setting.Timer.ScaleBreak = true
+	t.Cleanup(func() { lastWorkTime, lastWorkPlanned = 0, 0 })
+	breakPhase := planPhase{Minutes: 5, Break: true}
+
+	tests := []struct {
+		name    string
+		worked  time.Duration
+		planned time.Duration
+		want    time.Duration
+	}{
+		{"full session", 25 * time.Minute, 25 * time.Minute, 5 * time.Minute},
+		{"half session", 25 * time.Minute, 50 * time.Minute, 3 * time.Minute},
+		// 缩短到一分钟的专注换算后不足一分钟，休息仍保留一分钟
+		{"shortened session", time.Minute, time.Minute, 5 * time.Minute},
+		{"tiny ratio", time.Minute, 25 * time.Minute, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastWorkTime, lastWorkPlanned = tt.worked, tt.planned
+			if got := phaseDuration(breakPhase); got != tt.want {
+				t.Errorf("phaseDuration() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePlansDropsInvalidPhases(t *testing.T) {
+	logger = &Logger{Logger: log.New(io.Discard, "", 0)}
+	setting = defaultSettings()
+	setting.Timer.Plans = []timerPlan{
+		{Name: "mixed", Phases: []planPhase{{Minutes: 50}, {Minutes: 0, Break: true}, {Minutes: -3}, {Minutes: 10, Break: true}}},
+		{Name: "empty", Phases: []planPhase{{Minutes: 0}}},
+	}
+	setting.Timer.ActivePlan = "empty"
+
+	normalizePlans()
+	if len(setting.Timer.Plans) != 1 || setting.Timer.Plans[0].Name != "mixed" {
+		t.Fatalf("plans = %+v, want only mixed", setting.Timer.Plans)
+	}
+	if phases := setting.Timer.Plans[0].Phases; len(phases) != 2 || phases[0].Minutes != 50 || phases[1].Minutes != 10 {
+		t.Errorf("phases = %+v, want 50 and 10 minutes", phases)
+	}
+	if setting.Timer.ActivePlan != "" {
+		t.Errorf("ActivePlan = %q, want the dropped plan cleared", setting.Timer.ActivePlan)
+	}
+}
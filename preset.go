@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// timerPreset 常用的专注/休息时长组合
+type timerPreset struct {
+	Name      string `json:"name"`
+	WorkTime  int    `json:"workTime"`
+	BreakTime int    `json:"breakTime"`
+}
+
+var presetSelect *widget.Select
+
+func defaultPresets() []timerPreset {
+	return []timerPreset{
+		{Name: "25/5", WorkTime: 25, BreakTime: 5},
+		{Name: "45/15", WorkTime: 45, BreakTime: 15},
+		{Name: "90/20", WorkTime: 90, BreakTime: 20},
+	}
+}
+
+func findPreset(name string) *timerPreset {
+	for i := range setting.Timer.Presets {
+		if setting.Timer.Presets[i].Name == name {
+			return &setting.Timer.Presets[i]
+		}
+	}
+	return nil
+}
+
+func presetNames() []string {
+	names := make([]string, 0, len(setting.Timer.Presets))
+	for _, preset := range setting.Timer.Presets {
+		names = append(names, preset.Name)
+	}
+	return names
+}
+
+// applyPreset 切换到指定预设，只在空闲时生效
+func applyPreset(name string) {
+	preset := findPreset(name)
+	if preset == nil || currentState != stateIdle {
+		return
+	}
+	changeSettings(func(s *settings) {
+		s.Timer.ActivePreset = preset.Name
+		s.Timer.WorkTime = preset.WorkTime
+		s.Timer.BreakTime = preset.BreakTime
+	})
+	resetTimer()
+}
+
+// clearPresetIfChanged 手动修改时长后不再显示原预设
+func clearPresetIfChanged() {
+	preset := findPreset(setting.Timer.ActivePreset)
+	if preset == nil || (preset.WorkTime == setting.Timer.WorkTime && preset.BreakTime == setting.Timer.BreakTime) {
+		return
+	}
+	setting.Timer.ActivePreset = ""
+	refreshPresetSelect()
+}
+
+// createPresetSelect 主窗口中的预设快速切换
+func createPresetSelect() fyne.CanvasObject {
+	presetSelect = widget.NewSelect(presetNames(), applyPreset)
+	presetSelect.PlaceHolder = tr("preset.placeholder")
+	if findPreset(setting.Timer.ActivePreset) != nil {
+		presetSelect.Selected = setting.Timer.ActivePreset
+	}
+	return presetSelect
+}
+
+// refreshPresetSelect 预设变化或计时状态变化后同步主窗口下拉框
+func refreshPresetSelect() {
+	if presetSelect == nil {
+		return
+	}
+	presetSelect.Options = presetNames()
+	presetSelect.Selected = ""
+	if findPreset(setting.Timer.ActivePreset) != nil {
+		presetSelect.Selected = setting.Timer.ActivePreset
+	}
+	if currentState == stateIdle {
+		presetSelect.Enable()
+	} else {
+		presetSelect.Disable()
+	}
+	presetSelect.Refresh()
+}
+
+// createPresetSettings 设置窗口中把当前时长保存为预设或删除预设
+func createPresetSettings() fyne.CanvasObject {
+	manageSelect := widget.NewSelect(presetNames(), nil)
+	manageSelect.PlaceHolder = tr("preset.choose")
+
+	saveBtn := widget.NewButton(tr("preset.saveCurrent"), func() {
+		nameEntry := widget.NewEntry()
+		nameEntry.SetText(fmt.Sprintf("%d/%d", setting.Timer.WorkTime, setting.Timer.BreakTime))
+		dialog.ShowForm(tr("preset.saveTitle"), tr("button.save"), tr("button.cancel"),
+			[]*widget.FormItem{widget.NewFormItem(tr("preset.name"), nameEntry)},
+			func(confirmed bool) {
+				name := strings.TrimSpace(nameEntry.Text)
+				if !confirmed || name == "" {
+					return
+				}
+				preset := timerPreset{Name: name, WorkTime: setting.Timer.WorkTime, BreakTime: setting.Timer.BreakTime}
+				if existing := findPreset(name); existing != nil {
+					*existing = preset
+				} else {
+					setting.Timer.Presets = append(setting.Timer.Presets, preset)
+				}
+				setting.Timer.ActivePreset = name
+				manageSelect.Options = presetNames()
+				manageSelect.Refresh()
+				refreshPresetSelect()
+			}, settingsWindow)
+	})
+
+	deleteBtn := widget.NewButton(tr("button.delete"), func() {
+		name := manageSelect.Selected
+		for i := range setting.Timer.Presets {
+			if setting.Timer.Presets[i].Name == name {
+				setting.Timer.Presets = append(setting.Timer.Presets[:i], setting.Timer.Presets[i+1:]...)
+				break
+			}
+		}
+		if setting.Timer.ActivePreset == name {
+			setting.Timer.ActivePreset = ""
+		}
+		manageSelect.ClearSelected()
+		manageSelect.Options = presetNames()
+		manageSelect.Refresh()
+		refreshPresetSelect()
+	})
+
+	return container.NewHBox(manageSelect, layout.NewSpacer(), saveBtn, deleteBtn)
+}
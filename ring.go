@@ -0,0 +1,222 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// 进度环显示方式
+const (
+	ringOff     = "off"
+	ringWrap    = "wrap"
+	ringReplace = "replace"
+)
+
+var ringModeOrder = []string{ringOff, ringWrap, ringReplace}
+
+const (
+	// ringThickness 环的粗细占半径的比例
+	ringThickness = 0.1
+	// ringAnimation 每次进度变化的动画时长
+	ringAnimation = 400 * time.Millisecond
+	// 外环模式下倒计时文字缩小到能放进环内
+	ringWrapTextSize = 48
+	ringWrapSize     = 240
+	ringReplaceSize  = 260
+)
+
+var (
+	timeDisplay *fyne.Container
+	timeRing    *progressRing
+)
+
+// progressRing 圆环进度，value 为剩余比例，从正上方顺时针绘制
+type progressRing struct {
+	widget.BaseWidget
+	value   float64
+	color   color.Color
+	minSize fyne.Size
+	raster  *canvas.Raster
+	anim    *fyne.Animation
+}
+
+func newProgressRing(c color.Color, size fyne.Size) *progressRing {
+	p := &progressRing{value: 1, color: c, minSize: size}
+	p.raster = canvas.NewRasterWithPixels(p.pixel)
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+// SetValue 以动画过渡到新的剩余比例，大幅跳变（如重置）直接显示
+func (p *progressRing) SetValue(value float64) {
+	value = math.Max(0, math.Min(1, value))
+	if p.anim != nil {
+		p.anim.Stop()
+		p.anim = nil
+	}
+	from := p.value
+	if math.Abs(value-from) > 0.2 {
+		p.value = value
+		p.raster.Refresh()
+		return
+	}
+	p.anim = fyne.NewAnimation(ringAnimation, func(done float32) {
+		p.value = from + (value-from)*float64(done)
+		p.raster.Refresh()
+	})
+	p.anim.Start()
+}
+
+func (p *progressRing) SetColor(c color.Color) {
+	if p.color == c {
+		return
+	}
+	p.color = c
+	p.raster.Refresh()
+}
+
+func (p *progressRing) pixel(x, y, w, h int) color.Color {
+	return ringPixel(x, y, w, h, p.value, ringThickness, p.color)
+}
+
+func (p *progressRing) CreateRenderer() fyne.WidgetRenderer {
+	return &progressRingRenderer{ring: p}
+}
+
+type progressRingRenderer struct {
+	ring *progressRing
+}
+
+func (r *progressRingRenderer) Layout(size fyne.Size) {
+	r.ring.raster.Resize(size)
+	r.ring.raster.Move(fyne.NewPos(0, 0))
+}
+
+func (r *progressRingRenderer) MinSize() fyne.Size {
+	return r.ring.minSize
+}
+
+func (r *progressRingRenderer) Refresh() {
+	r.ring.raster.Refresh()
+}
+
+func (r *progressRingRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.ring.raster}
+}
+
+func (r *progressRingRenderer) Destroy() {
+	if r.ring.anim != nil {
+		r.ring.anim.Stop()
+	}
+}
+
+// ringPixel 计算环上一个像素的颜色，边缘做抗锯齿，未覆盖部分画成浅色轨道
+func ringPixel(x, y, w, h int, value, thickness float64, c color.Color) color.Color {
+	cx, cy := float64(w)/2, float64(h)/2
+	radius := math.Min(cx, cy) - 1
+	inner := radius * (1 - thickness)
+	dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+	dist := math.Hypot(dx, dy)
+	coverage := math.Min(math.Min(dist-inner, radius-dist)+0.5, 1)
+	if coverage <= 0 {
+		return color.Transparent
+	}
+
+	angle := math.Atan2(dx, -dy)
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+	fill := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if angle/(2*math.Pi) > value {
+		fill = color.NRGBA{R: 128, G: 128, B: 128, A: 50}
+	}
+	fill.A = uint8(float64(fill.A) * coverage)
+	return fill
+}
+
+// sessionRemaining 返回当前会话剩余比例，正计时没有总时长时视为满环
+func sessionRemaining() float64 {
+	if timerMode() == modeStopwatch {
+		return 1
+	}
+	return 1 - sessionProgress()
+}
+
+func ringModeLabel(mode string) string {
+	return tr("ring.mode." + mode)
+}
+
+// createTimeDisplay 返回主界面中的倒计时区域，按设置显示文字、外环或只显示环
+func createTimeDisplay() fyne.CanvasObject {
+	timeDisplay = container.NewCenter()
+	applyRingMode()
+	return timeDisplay
+}
+
+// applyRingMode 设置变化后重新组织倒计时区域
+func applyRingMode() {
+	if timeDisplay == nil {
+		return
+	}
+	switch setting.Appearance.RingMode {
+	case ringWrap:
+		timeRing = newProgressRing(timeText.Color, fyne.NewSize(ringWrapSize, ringWrapSize))
+		timeDisplay.Objects = []fyne.CanvasObject{container.NewStack(timeRing, container.NewCenter(timeText))}
+	case ringReplace:
+		timeRing = newProgressRing(timeText.Color, fyne.NewSize(ringReplaceSize, ringReplaceSize))
+		timeDisplay.Objects = []fyne.CanvasObject{timeRing}
+	default:
+		timeRing = nil
+		timeDisplay.Objects = []fyne.CanvasObject{timeText}
+	}
+	if timeRing != nil {
+		timeRing.value = sessionRemaining()
+	}
+	// 不同显示方式需要的空间不同，重新计算缩放
+	applyUIScale()
+	refreshMainLayout()
+}
+
+// updateRing 计时刷新时同步进度环
+func updateRing() {
+	if timeRing == nil {
+		return
+	}
+	timeRing.SetColor(timeText.Color)
+	timeRing.SetValue(sessionRemaining())
+}
+
+// createRingSettings 设置窗口中的进度环显示方式和动态图标
+func createRingSettings() fyne.CanvasObject {
+	labels := make([]string, 0, len(ringModeOrder))
+	for _, mode := range ringModeOrder {
+		labels = append(labels, ringModeLabel(mode))
+	}
+	modeSelect := widget.NewSelect(labels, func(selected string) {
+		for _, mode := range ringModeOrder {
+			if ringModeLabel(mode) == selected && mode != setting.Appearance.RingMode {
+				setting.Appearance.RingMode = mode
+				applyRingMode()
+			}
+		}
+	})
+	mode := setting.Appearance.RingMode
+	if mode != ringWrap && mode != ringReplace {
+		mode = ringOff
+	}
+	modeSelect.SetSelected(ringModeLabel(mode))
+
+	iconCheck := widget.NewCheck(tr("ring.icon"), func(checked bool) {
+		setting.Appearance.RingIcon = checked
+		updateTray()
+		updateStatusIcon()
+	})
+	iconCheck.SetChecked(setting.Appearance.RingIcon)
+	return container.NewHBox(modeSelect, iconCheck)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"database/sql"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	CREATE_ACTIVE_SQL = `
+        CREATE TABLE IF NOT EXISTS active_session (
+            id INTEGER PRIMARY KEY CHECK (id = 1),
+            mode TEXT NOT NULL,
+            plan TEXT NOT NULL,
+            phase_index INTEGER NOT NULL,
+            phase_state INTEGER NOT NULL,
+            start_time TEXT NOT NULL,
+            running_ms INTEGER NOT NULL,
+            total_ms INTEGER NOT NULL,
+            adjusted_ms INTEGER NOT NULL,
+            paused INTEGER NOT NULL,
+            overtime INTEGER NOT NULL,
+            updated_at TEXT NOT NULL
+        )
+    `
+	SAVE_ACTIVE_SQL = `INSERT OR REPLACE INTO active_session
+        (id, mode, plan, phase_index, phase_state, start_time, running_ms, total_ms, adjusted_ms, paused, overtime, updated_at)
+        VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	LOAD_ACTIVE_SQL = `SELECT mode, plan, phase_index, phase_state, start_time, running_ms, total_ms, adjusted_ms, paused, overtime, updated_at
+        FROM active_session WHERE id = 1`
+	CLEAR_ACTIVE_SQL = "DELETE FROM active_session"
+)
+
+// 运行中每隔这么久保存一次进度
+const persistInterval = 15 * time.Second
+
+// activeSession 进行中的会话快照，用于异常退出后恢复
+type activeSession struct {
+	Mode         string
+	Plan         string
+	PhaseIndex   int
+	PhaseState   state
+	StartTime    time.Time
+	RunningTime  time.Duration
+	Total        time.Duration
+	AdjustedTime time.Duration
+	Paused       bool
+	Overtime     bool
+	UpdatedAt    time.Time
+}
+
+var (
+	lastPersist time.Time
+	// pendingSession 启动时读到的未完成会话，用户选择前不覆盖数据库中的记录
+	pendingSession *activeSession
+)
+
+// persistSession 保存当前会话，空闲时不保存
+func persistSession() {
+	if db == nil || pendingSession != nil || currentState == stateIdle {
+		return
+	}
+	running := elapsedRunning()
+	session := activeSession{
+		Mode:         timerMode(),
+		Plan:         setting.Timer.ActivePlan,
+		PhaseIndex:   phaseIndex,
+		PhaseState:   nextState,
+		StartTime:    startTime,
+		RunningTime:  running,
+		Total:        total,
+		AdjustedTime: adjustedTime,
+		Paused:       !isRunning,
+		Overtime:     overtime,
+		UpdatedAt:    time.Now(),
+	}
+	if err := saveActiveSession(session); err != nil {
+		logError("save active session error", err)
+	}
+	lastPersist = time.Now()
+}
+
+// persistSessionPeriodically 在计时循环中调用，按间隔保存进度
+func persistSessionPeriodically() {
+	if time.Since(lastPersist) >= persistInterval {
+		persistSession()
+	}
+}
+
+func clearSession() {
+	if db == nil || pendingSession != nil {
+		return
+	}
+	if _, err := db.Exec(CLEAR_ACTIVE_SQL); err != nil {
+		logError("clear active session error", err)
+	}
+}
+
+func saveActiveSession(s activeSession) error {
+	_, err := db.Exec(
+		SAVE_ACTIVE_SQL,
+		s.Mode,
+		s.Plan,
+		s.PhaseIndex,
+		int(s.PhaseState),
+		s.StartTime.Format(time.RFC3339Nano),
+		s.RunningTime.Milliseconds(),
+		s.Total.Milliseconds(),
+		s.AdjustedTime.Milliseconds(),
+		s.Paused,
+		s.Overtime,
+		s.UpdatedAt.Format(time.RFC3339Nano),
+	)
+	return err
+}
+
+func loadActiveSession() (*activeSession, error) {
+	var (
+		s                         activeSession
+		phaseState                int
+		startText, updatedText    string
+		runningMs, totalMs, adjMs int64
+	)
+	err := db.QueryRow(LOAD_ACTIVE_SQL).Scan(
+		&s.Mode, &s.Plan, &s.PhaseIndex, &phaseState, &startText,
+		&runningMs, &totalMs, &adjMs, &s.Paused, &s.Overtime, &updatedText,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.PhaseState = state(phaseState)
+	s.RunningTime = time.Duration(runningMs) * time.Millisecond
+	s.Total = time.Duration(totalMs) * time.Millisecond
+	s.AdjustedTime = time.Duration(adjMs) * time.Millisecond
+	if s.StartTime, err = time.Parse(time.RFC3339Nano, startText); err != nil {
+		return nil, err
+	}
+	if s.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedText); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// loadPendingSession 启动时读取上次未完成的会话
+func loadPendingSession() {
+	saved, err := loadActiveSession()
+	if err != nil {
+		logError("load active session error", err)
+		clearSession()
+		return
+	}
+	pendingSession = saved
+}
+
+// offerSessionResume 有未完成的会话时询问继续、完成还是放弃，返回是否询问
+func offerSessionResume() bool {
+	saved := pendingSession
+	if saved == nil {
+		return false
+	}
+
+	// 关闭前仍在计时的会话，把关闭期间的时间也算作已用时间
+	running := saved.RunningTime
+	closedFor := time.Since(saved.UpdatedAt)
+	if !saved.Paused && closedFor > 0 {
+		running += closedFor
+	}
+
+	label := modeLabel(saved.Mode)
+	if saved.Mode == modePomodoro {
+		label = tr("phase.work")
+		if saved.PhaseState == stateBreaking {
+			label = tr("phase.break")
+		}
+	}
+	message := tr("resume.message", label, formatDuration(running))
+	if saved.Mode != modeStopwatch {
+		left := saved.Total - running
+		if left > 0 {
+			message += tr("resume.left", formatDuration(left))
+		} else {
+			message += tr("resume.timeUp")
+		}
+	}
+
+	var resumeDialog dialog.Dialog
+	resumeBtn := widget.NewButton(tr("resume.continue"), func() {
+		resumeDialog.Hide()
+		pendingSession = nil
+		restoreSession(saved, running)
+		if !saved.Paused {
+			startTimer()
+		}
+	})
+	resumeBtn.Importance = widget.HighImportance
+	completeBtn := widget.NewButton(tr("resume.complete"), func() {
+		resumeDialog.Hide()
+		pendingSession = nil
+		restoreSession(saved, running)
+		completeRestoredSession()
+	})
+	discardBtn := widget.NewButton(tr("resume.discard"), func() {
+		resumeDialog.Hide()
+		pendingSession = nil
+		clearSession()
+		resetTimer()
+	})
+
+	resumeDialog = dialog.NewCustomWithoutButtons(tr("resume.title"), container.NewVBox(
+		container.NewCenter(canvas.NewText(message, theme.TextColor())),
+		container.NewHBox(layout.NewSpacer(), discardBtn, completeBtn, resumeBtn, layout.NewSpacer()),
+	), window)
+	resumeDialog.Resize(fyne.NewSize(360, 160))
+	resumeDialog.Show()
+	return true
+}
+
+// restoreSession 把保存的会话恢复为暂停状态
+func restoreSession(saved *activeSession, running time.Duration) {
+	changeSettings(func(s *settings) {
+		s.Timer.TimerMode = saved.Mode
+		if findPlan(saved.Plan) != nil || saved.Plan == "" {
+			s.Timer.ActivePlan = saved.Plan
+		}
+	})
+	phaseIndex = saved.PhaseIndex % len(activePhases())
+	nextState = saved.PhaseState
+	startTime = saved.StartTime
+	total = saved.Total
+	adjustedTime = saved.AdjustedTime
+	overtime = saved.Overtime
+	totalRunningTime = running
+	remaining = total - totalRunningTime
+	warnedOffsets = make(map[int]bool)
+
+	isRunning = false
+	transitionState(statePause)
+	timeText.Color = sessionColor()
+	updateTimeText(displayTime())
+	persistSession()
+}
+
+// completeRestoredSession 按恢复的进度直接完成会话并记录
+func completeRestoredSession() {
+	if timerMode() == modeStopwatch {
+		finishFreeTimer()
+		return
+	}
+	if totalRunningTime > total && !setting.Timer.FlowMode {
+		totalRunningTime = total
+	}
+	overtime = false
+	currentState = nextState
+	go timerComplete()
+}
@@ -0,0 +1,740 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	settingsFile = "settings.json"
+	// settingsVersion 配置文件格式版本，没有版本号的是早期不分组的平铺格式
+	settingsVersion = 2
+)
+
+// legacyKeys 平铺格式中的键在分组格式中的位置，写成 "分组" 或 "分组.新键名"
+var legacyKeys = map[string]string{
+	"workTime":          "timer",
+	"breakTime":         "timer",
+	"adjustMinutes":     "timer",
+	"flowMode":          "timer",
+	"scaleBreak":        "timer",
+	"timerMode":         "timer",
+	"countdownMinutes":  "timer",
+	"plans":             "timer",
+	"activePlan":        "timer",
+	"presets":           "timer",
+	"activePreset":      "timer",
+	"autoStartBreak":    "timer",
+	"autoStartWork":     "timer",
+	"autoStartDelay":    "timer",
+	"autoStartOnLaunch": "timer",
+	"workHoursStart":    "timer",
+	"workHoursEnd":      "timer",
+	"strictBreak":       "timer",
+	"strictSkipDelay":   "timer",
+	"strictSkipConfirm": "timer",
+	"strictSkipsPerDay": "timer",
+	"followMonitor":     "timer",
+	"theme":             "appearance",
+	"themeFollowSystem": "appearance",
+	"themes":            "appearance",
+	"workColorText":     "appearance",
+	"breakColorText":    "appearance",
+	"noteColorText":     "appearance",
+	"BgColorText":       "appearance.bgColorText",
+	"statColorText":     "appearance",
+	"recentColors":      "appearance",
+	"ringMode":          "appearance",
+	"ringIcon":          "appearance",
+	"titleTime":         "appearance",
+	"timeIcon":          "appearance",
+	"layout":            "appearance",
+	"zoom":              "appearance",
+	"bgImagePath":       "appearance",
+	"bgBreakImagePath":  "appearance",
+	"bgImageMode":       "appearance",
+	"bgImageDim":        "appearance",
+	"bgImageBlur":       "appearance",
+	"language":          "appearance",
+	"workInformPath":    "sounds",
+	"warnInformPath":    "sounds",
+	"warnOffsets":       "sounds",
+	"flashSeconds":      "sounds",
+	"sleepPolicy":       "data",
+	"idlePause":         "integrations",
+	"idleMinutes":       "integrations",
+	"lockPause":         "integrations",
+	"minimizeToTray":    "integrations",
+	"shortcuts":         "shortcuts.bindings",
+	"globalHotkey":      "shortcuts",
+	"width":             "window",
+	"height":            "window",
+	"windowX":           "window.x",
+	"windowY":           "window.y",
+	"windowMonitor":     "window.monitor",
+	"miniWidth":         "window",
+	"miniHeight":        "window",
+	"miniX":             "window",
+	"miniY":             "window",
+}
+
+// intRange 整数设置项的取值范围，包含两端
+type intRange struct {
+	min, max int
+}
+
+var (
+	workTimeRange    = intRange{1, 240}
+	breakTimeRange   = intRange{1, 120}
+	flashRange       = intRange{0, 60}
+	adjustRange      = intRange{1, 60}
+	autoDelayRange   = intRange{0, 60}
+	skipDelayRange   = intRange{0, 600}
+	dailySkipsRange  = intRange{0, 20}
+	idleMinutesRange = intRange{1, 120}
+	countdownRange   = intRange{1, 1440}
+	warnOffsetRange  = intRange{1, 3600}
+)
+
+func (r intRange) check(value int) error {
+	if value < r.min || value > r.max {
+		return errors.New(tr("settings.error.range", r.min, r.max))
+	}
+	return nil
+}
+
+var (
+	// settingsSnapshot 打开设置窗口或上次应用时的设置，取消时恢复
+	settingsSnapshot *settings
+	// settingsChecks 设置窗口中各输入框的校验，全部通过才能应用
+	settingsChecks []func() error
+	saveMutex      sync.Mutex
+	// settingsTab 设置窗口当前的分页，重建内容后保持不变
+	settingsTab int
+)
+
+// settings 按设置窗口的分页分成几组，窗口位置等运行状态单独一组
+type settings struct {
+	Version      int                 `json:"version"`
+	Timer        timerSettings       `json:"timer"`
+	Appearance   appearanceSettings  `json:"appearance"`
+	Sounds       soundSettings       `json:"sounds"`
+	Data         dataSettings        `json:"data"`
+	Integrations integrationSettings `json:"integrations"`
+	Shortcuts    shortcutSettings    `json:"shortcuts"`
+	Window       windowSettings      `json:"window"`
+	workPathText *widget.Label
+	warnPathText *widget.Label
+	bgPathText   *widget.Label
+}
+
+// timerSettings 计时时长、方案、自动开始和严格休息
+type timerSettings struct {
+	WorkTime          int           `json:"workTime"`
+	BreakTime         int           `json:"breakTime"`
+	AdjustMinutes     int           `json:"adjustMinutes"`
+	FlowMode          bool          `json:"flowMode"`
+	ScaleBreak        bool          `json:"scaleBreak"`
+	TimerMode         string        `json:"timerMode"`
+	CountdownMinutes  int           `json:"countdownMinutes"`
+	Plans             []timerPlan   `json:"plans"`
+	ActivePlan        string        `json:"activePlan"`
+	Presets           []timerPreset `json:"presets"`
+	ActivePreset      string        `json:"activePreset"`
+	AutoStartBreak    bool          `json:"autoStartBreak"`
+	AutoStartWork     bool          `json:"autoStartWork"`
+	AutoStartDelay    int           `json:"autoStartDelay"`
+	AutoStartOnLaunch bool          `json:"autoStartOnLaunch"`
+	WorkHoursStart    string        `json:"workHoursStart"`
+	WorkHoursEnd      string        `json:"workHoursEnd"`
+	StrictBreak       bool          `json:"strictBreak"`
+	StrictSkipDelay   int           `json:"strictSkipDelay"`
+	StrictSkipConfirm bool          `json:"strictSkipConfirm"`
+	StrictSkipsPerDay int           `json:"strictSkipsPerDay"`
+	FollowMonitor     bool          `json:"followMonitor"`
+}
+
+// appearanceSettings 颜色、主题、进度环、背景、布局和语言
+type appearanceSettings struct {
+	Theme             string        `json:"theme"`
+	ThemeFollowSystem bool          `json:"themeFollowSystem"`
+	Themes            []themePreset `json:"themes"`
+	WorkColorText     string        `json:"workColorText"`
+	BreakColorText    string        `json:"breakColorText"`
+	NoteColorText     string        `json:"noteColorText"`
+	BgColorText       string        `json:"bgColorText"`
+	StatColorText     string        `json:"statColorText"`
+	RecentColors      []string      `json:"recentColors"`
+	RingMode          string        `json:"ringMode"`
+	RingIcon          bool          `json:"ringIcon"`
+	TitleTime         bool          `json:"titleTime"`
+	TimeIcon          bool          `json:"timeIcon"`
+	Layout            string        `json:"layout"`
+	Zoom              float32       `json:"zoom"`
+	BgImagePath       string        `json:"bgImagePath"`
+	BgBreakImagePath  string        `json:"bgBreakImagePath"`
+	BgImageMode       string        `json:"bgImageMode"`
+	BgImageDim        float64       `json:"bgImageDim"`
+	BgImageBlur       bool          `json:"bgImageBlur"`
+	Language          string        `json:"language"`
+}
+
+// soundSettings 铃声和结束前提醒
+type soundSettings struct {
+	WorkInformPath string `json:"workInformPath"`
+	WarnInformPath string `json:"warnInformPath"`
+	WarnOffsets    []int  `json:"warnOffsets"`
+	FlashSeconds   int    `json:"flashSeconds"`
+}
+
+// dataSettings 影响计时记录的设置
+type dataSettings struct {
+	SleepPolicy string `json:"sleepPolicy"`
+}
+
+// integrationSettings 与桌面系统的联动
+type integrationSettings struct {
+	IdlePause      bool `json:"idlePause"`
+	IdleMinutes    int  `json:"idleMinutes"`
+	LockPause      bool `json:"lockPause"`
+	MinimizeToTray bool `json:"minimizeToTray"`
+}
+
+// shortcutSettings 窗口内快捷键和全局热键
+type shortcutSettings struct {
+	Bindings     map[string]string `json:"bindings"`
+	GlobalHotkey string            `json:"globalHotkey"`
+}
+
+// windowSettings 主窗口和迷你窗口的大小、位置，关闭时自动记录
+type windowSettings struct {
+	Width      float32 `json:"width"`
+	Height     float32 `json:"height"`
+	X          int     `json:"x"`
+	Y          int     `json:"y"`
+	Monitor    string  `json:"monitor"`
+	MiniWidth  float32 `json:"miniWidth"`
+	MiniHeight float32 `json:"miniHeight"`
+	MiniX      int     `json:"miniX"`
+	MiniY      int     `json:"miniY"`
+}
+
+func defaultSettings() *settings {
+	return &settings{
+		Version:      settingsVersion,
+		Timer:        defaultTimerSettings(),
+		Appearance:   defaultAppearanceSettings(),
+		Sounds:       defaultSoundSettings(),
+		Data:         defaultDataSettings(),
+		Integrations: defaultIntegrationSettings(),
+		Shortcuts:    defaultShortcutSettings(),
+		Window:       defaultWindowSettings(),
+	}
+}
+
+func defaultTimerSettings() timerSettings {
+	return timerSettings{
+		WorkTime:          45,
+		BreakTime:         15,
+		AdjustMinutes:     5,
+		TimerMode:         modePomodoro,
+		CountdownMinutes:  10,
+		Presets:           defaultPresets(),
+		ActivePreset:      "45/15",
+		AutoStartDelay:    5,
+		WorkHoursStart:    "09:00",
+		WorkHoursEnd:      "18:00",
+		StrictSkipDelay:   30,
+		StrictSkipsPerDay: 2,
+	}
+}
+
+func defaultAppearanceSettings() appearanceSettings {
+	return appearanceSettings{
+		WorkColorText:  colorToHex(defaultWorkColor),
+		BreakColorText: colorToHex(defaultBreakColor),
+		NoteColorText:  colorToHex(defaultNoteColor),
+		StatColorText:  colorToHex(defaultStatColor),
+		BgColorText:    colorToHex(defaultBgColor),
+		RingMode:       ringOff,
+		TitleTime:      true,
+		Layout:         layoutAuto,
+		Zoom:           1,
+		BgImageMode:    bgModeFill,
+		BgImageDim:     0.4,
+	}
+}
+
+func defaultSoundSettings() soundSettings {
+	return soundSettings{
+		WorkInformPath: defaultEmpty,
+		WarnOffsets:    []int{120},
+		FlashSeconds:   10,
+	}
+}
+
+func defaultDataSettings() dataSettings {
+	return dataSettings{
+		SleepPolicy: sleepPause,
+	}
+}
+
+func defaultIntegrationSettings() integrationSettings {
+	return integrationSettings{
+		IdleMinutes: 5,
+	}
+}
+
+func defaultShortcutSettings() shortcutSettings {
+	return shortcutSettings{
+		Bindings: defaultShortcuts(),
+	}
+}
+
+func defaultWindowSettings() windowSettings {
+	return windowSettings{
+		Width:  430,
+		Height: 238,
+	}
+}
+
+// migrateSettings 把早期平铺格式的配置转换为分组格式，返回转换后的内容和是否做了转换
+func migrateSettings(data []byte) ([]byte, bool, error) {
+	var flat map[string]json.RawMessage
+	if err := json.Unmarshal(data, &flat); err != nil {
+		return nil, false, err
+	}
+	if _, ok := flat["version"]; ok {
+		return data, false, nil
+	}
+
+	groups := make(map[string]map[string]json.RawMessage)
+	for key, value := range flat {
+		target, ok := legacyKeys[key]
+		if !ok {
+			logInfo("drop unknown setting %s", key)
+			continue
+		}
+		group, name := target, key
+		if i := strings.Index(target, "."); i >= 0 {
+			group, name = target[:i], target[i+1:]
+		}
+		if groups[group] == nil {
+			groups[group] = make(map[string]json.RawMessage)
+		}
+		groups[group][name] = value
+	}
+
+	nested := map[string]any{"version": settingsVersion}
+	for group, fields := range groups {
+		nested[group] = fields
+	}
+	migrated, err := json.Marshal(nested)
+	if err != nil {
+		return nil, false, err
+	}
+	return migrated, true, nil
+}
+
+// normalizeSettings 补全旧配置文件缺少的字段，修正明显不合理的值
+func normalizeSettings() {
+	setting.Version = settingsVersion
+	if setting.Timer.WorkTime <= 0 {
+		setting.Timer.WorkTime = 45
+	}
+	if setting.Timer.BreakTime <= 0 {
+		setting.Timer.BreakTime = 15
+	}
+	if setting.Appearance.StatColorText == "" {
+		setting.Appearance.StatColorText = colorToHex(statColor)
+	}
+	if setting.Appearance.WorkColorText == "" {
+		setting.Appearance.WorkColorText = colorToHex(workColor)
+	}
+	if setting.Appearance.BreakColorText == "" {
+		setting.Appearance.BreakColorText = colorToHex(breakColor)
+	}
+	if setting.Appearance.NoteColorText == "" {
+		setting.Appearance.NoteColorText = colorToHex(noteColor)
+	}
+	if setting.Appearance.BgColorText == "" {
+		setting.Appearance.BgColorText = colorToHex(bgColor)
+	}
+	if setting.Shortcuts.Bindings == nil {
+		setting.Shortcuts.Bindings = defaultShortcuts()
+	}
+	if setting.Timer.AdjustMinutes <= 0 {
+		setting.Timer.AdjustMinutes = 5
+	}
+	if setting.Timer.CountdownMinutes <= 0 {
+		setting.Timer.CountdownMinutes = 10
+	}
+	if setting.Integrations.IdleMinutes <= 0 {
+		setting.Integrations.IdleMinutes = 5
+	}
+	if setting.Appearance.Zoom < minZoom || setting.Appearance.Zoom > maxZoom {
+		setting.Appearance.Zoom = 1
+	}
+	normalizePlans()
+	if preset := findPreset(setting.Timer.ActivePreset); preset == nil ||
+		preset.WorkTime != setting.Timer.WorkTime || preset.BreakTime != setting.Timer.BreakTime {
+		setting.Timer.ActivePreset = ""
+	}
+}
+
+// validateSettings 检查设置是否可以保存，返回每个问题的说明
+func validateSettings(s *settings) []string {
+	var problems []string
+	add := func(labelKey string, err error) {
+		problems = append(problems, tr("settings.error.item", tr(labelKey), err.Error()))
+	}
+
+	ranges := []struct {
+		labelKey string
+		value    int
+		r        intRange
+	}{
+		{"settings.workTime", s.Timer.WorkTime, workTimeRange},
+		{"settings.breakTime", s.Timer.BreakTime, breakTimeRange},
+		{"settings.flash", s.Sounds.FlashSeconds, flashRange},
+		{"settings.adjustStep", s.Timer.AdjustMinutes, adjustRange},
+		{"settings.autoStart", s.Timer.AutoStartDelay, autoDelayRange},
+		{"settings.skipLimit", s.Timer.StrictSkipDelay, skipDelayRange},
+		{"settings.dailySkips", s.Timer.StrictSkipsPerDay, dailySkipsRange},
+		{"settings.idle", s.Integrations.IdleMinutes, idleMinutesRange},
+		{"mode.countdownMinutes", s.Timer.CountdownMinutes, countdownRange},
+	}
+	for _, item := range ranges {
+		if err := item.r.check(item.value); err != nil {
+			add(item.labelKey, err)
+		}
+	}
+	for _, offset := range s.Sounds.WarnOffsets {
+		if err := warnOffsetRange.check(offset); err != nil {
+			add("settings.warnOffsets", err)
+			break
+		}
+	}
+	for _, text := range []string{s.Timer.WorkHoursStart, s.Timer.WorkHoursEnd} {
+		if err := validateClock(text); err != nil {
+			add("settings.workHours", err)
+			break
+		}
+	}
+
+	files := []struct {
+		labelKey string
+		path     string
+	}{
+		{"settings.informSound", s.Sounds.WorkInformPath},
+		{"settings.warnSound", s.Sounds.WarnInformPath},
+		{"settings.bgImage", s.Appearance.BgImagePath},
+		{"settings.bgBreakImage", s.Appearance.BgBreakImagePath},
+	}
+	for _, file := range files {
+		if err := validateFile(file.path); err != nil {
+			add(file.labelKey, err)
+		}
+	}
+	return problems
+}
+
+func validateInt(text string, r intRange) error {
+	val, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return errors.New(tr("settings.error.range", r.min, r.max))
+	}
+	return r.check(val)
+}
+
+func validateClock(text string) error {
+	if _, err := time.Parse("15:04", strings.TrimSpace(text)); err != nil {
+		return errors.New(tr("settings.error.time"))
+	}
+	return nil
+}
+
+func validateOffsets(text string) error {
+	offsets, err := parseOffsets(text)
+	if err != nil {
+		return errors.New(tr("settings.error.offsets"))
+	}
+	for _, offset := range offsets {
+		if warnOffsetRange.check(offset) != nil {
+			return errors.New(tr("settings.error.offsets"))
+		}
+	}
+	return nil
+}
+
+// validateFile 可选的文件路径，未设置时不检查
+func validateFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return errors.New(tr("settings.error.file", filepath.Base(path)))
+	}
+	return nil
+}
+
+// settingsPage 设置窗口的一个分页，内容过长时滚动
+func settingsPage(items []*widget.FormItem) fyne.CanvasObject {
+	return container.NewVScroll(widget.NewForm(items...))
+}
+
+// createDataLocationItems 显示数据库和配置文件的位置，方便备份
+func createDataLocationItems() []*widget.FormItem {
+	var items []*widget.FormItem
+	for _, file := range []struct {
+		labelKey string
+		path     string
+	}{
+		{"settings.dbFile", databaseFile},
+		{"settings.settingsFile", settingsFile},
+	} {
+		path := file.path
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		pathLabel := widget.NewLabel(truncatePath(path, 50))
+		openBtn := widget.NewButton(tr("settings.openFolder"), func() {
+			dir, err := url.Parse(storage.NewFileURI(filepath.Dir(path)).String())
+			if err != nil {
+				logError("parse data folder error", err)
+				return
+			}
+			if err := myApp.OpenURL(dir); err != nil {
+				logError("open data folder error", err)
+			}
+		})
+		items = append(items, widget.NewFormItem(tr(file.labelKey), container.NewHBox(pathLabel, layout.NewSpacer(), openBtn)))
+	}
+	return items
+}
+
+// newCheckedEntry 带校验的输入框，错误显示在输入框后面，只有通过校验的内容才会交给 onValid
+func newCheckedEntry(label string, width float32, text string, validate func(string) error,
+	onValid func(string), extras ...fyne.CanvasObject) *fyne.Container {
+	box := newFixedWidthEntry(width, 36)
+	entry := box.Objects[0].(*widget.Entry)
+	entry.SetText(text)
+	entry.Validator = validate
+
+	errText := canvas.NewText("", theme.Color(theme.ColorNameError))
+	errText.TextSize = theme.CaptionTextSize()
+	entry.OnChanged = func(text string) {
+		err := validate(text)
+		errText.Text = ""
+		if err != nil {
+			errText.Text = err.Error()
+		}
+		errText.Refresh()
+		if err == nil {
+			onValid(strings.TrimSpace(text))
+		}
+	}
+	settingsChecks = append(settingsChecks, func() error {
+		if err := validate(entry.Text); err != nil {
+			return errors.New(tr("settings.error.item", label, err.Error()))
+		}
+		return nil
+	})
+
+	objects := append([]fyne.CanvasObject{box}, extras...)
+	objects = append(objects, container.NewCenter(errText))
+	return container.NewHBox(objects...)
+}
+
+// newIntField 整数设置项，超出范围时提示且不写入设置
+func newIntField(label string, width float32, value int, r intRange, onValid func(int), extras ...fyne.CanvasObject) *fyne.Container {
+	return newCheckedEntry(label, width, strconv.Itoa(value), func(text string) error {
+		return validateInt(text, r)
+	}, func(text string) {
+		val, _ := strconv.Atoi(text)
+		onValid(val)
+	}, extras...)
+}
+
+// cloneSettings 深拷贝设置，界面控件等未导出字段不复制
+func cloneSettings(s *settings) *settings {
+	data, err := json.Marshal(s)
+	if err != nil {
+		logError("clone settings error", err)
+		return s
+	}
+	c := &settings{}
+	if err := json.Unmarshal(data, c); err != nil {
+		logError("clone settings error", err)
+		return s
+	}
+	return c
+}
+
+// restoreSettings 用另一份设置替换当前设置，保留设置窗口中的控件引用。
+// 窗口大小和位置不在设置窗口中修改，始终保留当前值
+func restoreSettings(s *settings) {
+	restored := cloneSettings(s)
+	restored.Window = setting.Window
+	restored.workPathText = setting.workPathText
+	restored.warnPathText = setting.warnPathText
+	restored.bgPathText = setting.bgPathText
+	*setting = *restored
+}
+
+// reapplySettings 设置被整体替换后刷新所有实时生效的界面
+func reapplySettings() {
+	loadColors()
+	refreshColors()
+	applyRingMode()
+	refreshMainLayout()
+	applyAllShortcuts()
+	refreshPresetSelect()
+	updateTray()
+	updateStatusIcon()
+	updateWindowTitle(timeText.Text)
+	if currentState == stateIdle {
+		resetTimer()
+	}
+}
+
+// applySettings 校验通过后保存设置，窗口保持打开
+func applySettings() {
+	var problems []string
+	for _, check := range settingsChecks {
+		if err := check(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	problems = append(problems, validateSettings(setting)...)
+	if len(problems) > 0 {
+		dialog.ShowError(errors.New(strings.Join(problems, "\n")), settingsWindow)
+		return
+	}
+
+	previous := settingsSnapshot
+	settingsSnapshot = cloneSettings(setting)
+	if err := saveSettings(); err != nil {
+		settingsSnapshot = previous
+		dialog.ShowError(fmt.Errorf("%s: %w", tr("settings.saveFailed"), err), settingsWindow)
+		return
+	}
+	timeChanged := previous == nil ||
+		setting.Timer.WorkTime != previous.Timer.WorkTime || setting.Timer.BreakTime != previous.Timer.BreakTime
+
+	updateTimeColor()
+	registerGlobalHotkey(setting.Shortcuts.GlobalHotkey)
+	if timeChanged && currentState == stateIdle {
+		resetTimer()
+	}
+}
+
+// discardSettingsChanges 放弃设置窗口中未应用的修改
+func discardSettingsChanges() {
+	if settingsSnapshot == nil {
+		return
+	}
+	restoreSettings(settingsSnapshot)
+	settingsSnapshot = nil
+	reapplySettings()
+}
+
+// confirmRestoreDefaults 恢复默认设置，预设、方案、主题等用户数据和窗口位置保留，应用后才保存
+func confirmRestoreDefaults() {
+	dialog.ShowConfirm(tr("settings.restoreTitle"), tr("settings.restoreMessage"), func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		defaults := defaultSettings()
+		defaults.Window = setting.Window
+		defaults.Timer.Presets = setting.Timer.Presets
+		defaults.Timer.Plans = setting.Timer.Plans
+		defaults.Appearance.Themes = setting.Appearance.Themes
+		defaults.Appearance.RecentColors = setting.Appearance.RecentColors
+		restoreSettings(defaults)
+		normalizeSettings()
+		reapplySettings()
+		refreshSettingsContent()
+	}, settingsWindow)
+}
+
+// changeSettings 在设置窗口之外修改设置并立即保存。设置窗口打开时同时改到已应用的快照中，
+// 这样会被保存，取消设置窗口的修改时也不会被还原
+func changeSettings(change func(s *settings)) {
+	change(setting)
+	if settingsSnapshot != nil {
+		change(settingsSnapshot)
+	}
+	saveSettings()
+}
+
+// saveSettings 先写临时文件再重命名，避免写到一半时崩溃留下损坏的配置。
+// 设置窗口打开时只保存已应用的设置，未应用的修改仍是预览
+func saveSettings() error {
+	saveMutex.Lock()
+	defer saveMutex.Unlock()
+
+	saved := setting
+	if settingsSnapshot != nil {
+		saved = cloneSettings(settingsSnapshot)
+		saved.Window = setting.Window
+	}
+	jsonData, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		logError("编码设置失败:", err)
+		return err
+	}
+	if err := writeFileAtomic(settingsFile, jsonData, 0644); err != nil {
+		logError("保存设置失败:", err)
+		return err
+	}
+	return nil
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
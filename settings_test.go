@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"testing"
+)
+
+// flatSettings 早期不分组的配置文件，BgColorText 的键名沿用了当时的大写写法
+const flatSettings = `{
+  "workTime": 1,
+  "breakTime": 1,
+  "workInformPath": "/Users/huangfu/code/go/src/leo/kTimer/start.mp3",
+  "height": 214,
+  "width": 430,
+  "windowX": 120,
+  "windowY": 80,
+  "windowMonitor": "HDMI-1",
+  "workColorText": "#DF5D1F",
+  "breakColorText": "#7EA56A",
+  "noteColorText": "#7EA56A",
+  "BgColorText": "#FFFFFF",
+  "statColorText": "#7EA56A",
+  "shortcuts": {"toggle": "Space", "mini": "Ctrl+M"},
+  "globalHotkey": "Ctrl+Alt+P",
+  "breakInformPath": "/tmp/break.mp3"
+}`
+
+func TestMigrateFlatSettings(t *testing.T) {
+	logger = &Logger{Logger: log.New(io.Discard, "", 0)}
+
+	data, migrated, err := migrateSettings([]byte(flatSettings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !migrated {
+		t.Fatal("flat settings not migrated")
+	}
+
+	var nested map[string]json.RawMessage
+	if err := json.Unmarshal(data, &nested); err != nil {
+		t.Fatal(err)
+	}
+	for key := range nested {
+		switch key {
+		case "version", "timer", "appearance", "sounds", "shortcuts", "window":
+		default:
+			t.Errorf("unexpected top level key %q", key)
+		}
+	}
+
+	s := &settings{}
+	if err := json.Unmarshal(data, s); err != nil {
+		t.Fatal(err)
+	}
+	want := &settings{
+		Version: settingsVersion,
+		Timer:   timerSettings{WorkTime: 1, BreakTime: 1},
+		Appearance: appearanceSettings{
+			WorkColorText:  "#DF5D1F",
+			BreakColorText: "#7EA56A",
+			NoteColorText:  "#7EA56A",
+			BgColorText:    "#FFFFFF",
+			StatColorText:  "#7EA56A",
+		},
+		Sounds: soundSettings{WorkInformPath: "/Users/huangfu/code/go/src/leo/kTimer/start.mp3"},
+		Shortcuts: shortcutSettings{
+			Bindings:     map[string]string{"toggle": "Space", "mini": "Ctrl+M"},
+			GlobalHotkey: "Ctrl+Alt+P",
+		},
+		Window: windowSettings{Width: 430, Height: 214, X: 120, Y: 80, Monitor: "HDMI-1"},
+	}
+	got, _ := json.Marshal(s)
+	expected, _ := json.Marshal(want)
+	if !bytes.Equal(got, expected) {
+		t.Errorf("migrated settings\n got: %s\nwant: %s", got, expected)
+	}
+}
+
+func TestMigrateVersionedSettingsUnchanged(t *testing.T) {
+	logger = &Logger{Logger: log.New(io.Discard, "", 0)}
+	input := []byte(`{"version": 2, "timer": {"workTime": 30}, "appearance": {"bgColorText": "#000000"}}`)
+
+	data, migrated, err := migrateSettings(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated {
+		t.Error("versioned settings migrated again")
+	}
+	if !bytes.Equal(data, input) {
+		t.Errorf("versioned settings changed to %s", data)
+	}
+}
+
+func TestMigrateInvalidSettings(t *testing.T) {
+	logger = &Logger{Logger: log.New(io.Discard, "", 0)}
+	if _, _, err := migrateSettings([]byte(`{"workTime": `)); err == nil {
+		t.Error("no error for broken settings")
+	}
+}
+
+// TestLoadSettingsMigratesFile 读取旧配置后按新格式保存，原文件留作备份
+func TestLoadSettingsMigratesFile(t *testing.T) {
+	logger = &Logger{Logger: log.New(io.Discard, "", 0)}
+	chdirTemp(t)
+	if err := os.WriteFile(settingsFile, []byte(flatSettings), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loadSettings()
+	if setting.Timer.WorkTime != 1 || setting.Window.X != 120 || setting.Appearance.BgColorText != "#FFFFFF" {
+		t.Errorf("loaded settings = %+v", setting)
+	}
+
+	backup, err := os.ReadFile(settingsFile + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != flatSettings {
+		t.Errorf("backup = %s, want the original file", backup)
+	}
+
+	saved, err := os.ReadFile(settingsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var version struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(saved, &version); err != nil {
+		t.Fatal(err)
+	}
+	if version.Version != settingsVersion {
+		t.Errorf("saved version = %d, want %d", version.Version, settingsVersion)
+	}
+
+	// 再次读取时已是新格式，不会覆盖备份
+	if err := os.WriteFile(settingsFile+".bak", []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loadSettings()
+	if backup, _ := os.ReadFile(settingsFile + ".bak"); string(backup) != "old" {
+		t.Error("backup overwritten for settings already in the new format")
+	}
+	if setting.Timer.WorkTime != 1 || setting.Window.X != 120 {
+		t.Errorf("reloaded settings = %+v", setting)
+	}
+}
+
+// TestChangesOutsideSettingsWindowSurviveCancel 设置窗口打开时从主窗口切换模式和预设、移动迷你窗口，
+// 取消设置窗口的修改后这些改动仍然保留
+func TestChangesOutsideSettingsWindowSurviveCancel(t *testing.T) {
+	setupTestUI(t)
+	chdirTemp(t)
+	settingsSnapshot = cloneSettings(setting)
+	t.Cleanup(func() { settingsSnapshot = nil })
+
+	// 设置窗口中未应用的修改
+	setting.Sounds.FlashSeconds = 30
+
+	changeSettings(func(s *settings) {
+		s.Timer.TimerMode = modeStopwatch
+	})
+	applyPreset("25/5")
+	setting.Window.MiniX, setting.Window.MiniY = 100, 200
+
+	discardSettingsChanges()
+
+	if setting.Sounds.FlashSeconds != defaultSoundSettings().FlashSeconds {
+		t.Errorf("FlashSeconds = %d, want the unapplied change discarded", setting.Sounds.FlashSeconds)
+	}
+	if setting.Timer.TimerMode != modeStopwatch {
+		t.Errorf("TimerMode = %q, want %q", setting.Timer.TimerMode, modeStopwatch)
+	}
+	if setting.Timer.ActivePreset != "25/5" || setting.Timer.WorkTime != 25 || setting.Timer.BreakTime != 5 {
+		t.Errorf("preset = %q %d/%d, want 25/5", setting.Timer.ActivePreset, setting.Timer.WorkTime, setting.Timer.BreakTime)
+	}
+	if setting.Window.MiniX != 100 || setting.Window.MiniY != 200 {
+		t.Errorf("mini position = %d,%d, want 100,200", setting.Window.MiniX, setting.Window.MiniY)
+	}
+}
+
+// chdirTemp 切换到临时目录，保存的配置文件不会写到源码目录中
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(dir) })
+}
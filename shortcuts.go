@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+const (
+	actionToggle   = "toggle"
+	actionReset    = "reset"
+	actionSkip     = "skip"
+	actionExtend   = "extend"
+	actionShorten  = "shorten"
+	actionSettings = "settings"
+	actionMini     = "mini"
+)
+
+// shortcutActions 快捷键动作及其在设置窗口中名称的翻译 key，按显示顺序排列
+var shortcutActions = []struct {
+	name     string
+	labelKey string
+}{
+	{actionToggle, "action.toggle"},
+	{actionReset, "action.reset"},
+	{actionSkip, "action.skip"},
+	{actionExtend, "action.extend"},
+	{actionShorten, "action.shorten"},
+	{actionSettings, "action.settings"},
+	{actionMini, "action.mini"},
+}
+
+func defaultShortcuts() map[string]string {
+	return map[string]string{
+		actionToggle:   "Space",
+		actionReset:    "R",
+		actionSkip:     "S",
+		actionExtend:   "+",
+		actionShorten:  "-",
+		actionSettings: "Ctrl+,",
+		actionMini:     "Ctrl+M",
+	}
+}
+
+// registeredShortcuts 记录每个画布上已注册的组合键，重新绑定时先移除
+var registeredShortcuts = make(map[fyne.Canvas][]fyne.Shortcut)
+
+func runShortcutAction(action string) {
+	switch action {
+	case actionToggle:
+		toggleTimer()
+	case actionReset:
+		confirmReset()
+	case actionSkip:
+		guardStrictBreak(skipTimer)()
+	case actionExtend:
+		extendTimer()
+	case actionShorten:
+		shortenTimer()
+	case actionSettings:
+		showSettingsWindow()
+	case actionMini:
+		toggleMiniMode()
+	}
+}
+
+// applyShortcuts 把设置中的快捷键绑定到画布上，单键走 OnTypedKey，组合键走 AddShortcut
+func applyShortcuts(c fyne.Canvas) {
+	for _, shortcut := range registeredShortcuts[c] {
+		c.RemoveShortcut(shortcut)
+	}
+	registeredShortcuts[c] = nil
+
+	singleKeys := make(map[fyne.KeyName]string)
+	for action, binding := range setting.Shortcuts.Bindings {
+		key, mod, err := parseBinding(binding)
+		if err != nil {
+			if binding != "" {
+				logError("parse shortcut error", err)
+			}
+			continue
+		}
+		action := action
+		if mod == 0 {
+			singleKeys[key] = action
+			if key == fyne.KeyPlus {
+				singleKeys[fyne.KeyEqual] = action
+			}
+			continue
+		}
+		shortcut := &desktop.CustomShortcut{KeyName: key, Modifier: mod}
+		c.AddShortcut(shortcut, func(fyne.Shortcut) {
+			runShortcutAction(action)
+		})
+		registeredShortcuts[c] = append(registeredShortcuts[c], shortcut)
+	}
+
+	c.SetOnTypedKey(func(ev *fyne.KeyEvent) {
+		if action, ok := singleKeys[ev.Name]; ok {
+			runShortcutAction(action)
+		}
+	})
+}
+
+// applyAllShortcuts 在快捷键设置变化后重新绑定所有窗口
+func applyAllShortcuts() {
+	applyShortcuts(window.Canvas())
+	if miniWindow != nil {
+		applyShortcuts(miniWindow.Canvas())
+	}
+}
+
+// parseBinding 解析 "Ctrl+Shift+P" 形式的快捷键，最后一段为按键
+func parseBinding(binding string) (fyne.KeyName, fyne.KeyModifier, error) {
+	binding = strings.TrimSpace(binding)
+	if binding == "" {
+		return "", 0, fmt.Errorf("empty shortcut")
+	}
+
+	// 从倒数第二个字符往前找分隔符，这样 "Ctrl++" 的按键也能解析为 "+"
+	key, modPart := binding, ""
+	if i := strings.LastIndex(binding[:len(binding)-1], "+"); i >= 0 {
+		modPart, key = binding[:i], binding[i+1:]
+	}
+
+	var mod fyne.KeyModifier
+	for _, part := range strings.Split(modPart, "+") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "":
+		case "ctrl", "control":
+			mod |= fyne.KeyModifierControl
+		case "shift":
+			mod |= fyne.KeyModifierShift
+		case "alt":
+			mod |= fyne.KeyModifierAlt
+		case "super", "cmd", "win":
+			mod |= fyne.KeyModifierSuper
+		default:
+			return "", 0, fmt.Errorf("unknown modifier: %s", part)
+		}
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", 0, fmt.Errorf("missing key: %s", binding)
+	}
+	if len(key) == 1 {
+		key = strings.ToUpper(key)
+	} else if strings.EqualFold(key, "space") {
+		key = string(fyne.KeySpace)
+	}
+	return fyne.KeyName(key), mod, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// 休眠处理方式
+const (
+	sleepPause = "pause"
+	sleepCount = "count"
+	sleepAsk   = "ask"
+)
+
+var sleepPolicyOrder = []string{sleepPause, sleepCount, sleepAsk}
+
+func sleepPolicyLabel(policy string) string {
+	return tr("sleep." + policy)
+}
+
+// 两次计时之间时钟跳变超过这个值才视为休眠或改了系统时间
+const clockJumpThreshold = 30 * time.Second
+
+var (
+	// clockStart 用 Go 单调时钟读数的平台以它为起点
+	clockStart    = time.Now()
+	lastTick      time.Time
+	lastTickAwake time.Duration
+	lastTickClock time.Duration
+	// sleepGap 本次会话中检测到的休眠总时长，随记录保存
+	sleepGap time.Duration
+)
+
+// resetClockCheck 开始计时时记录时钟起点
+func resetClockCheck() {
+	lastTick = time.Now()
+	lastTickAwake = awakeClock()
+	lastTickClock = suspendClock()
+}
+
+// detectSleep 每次计时时调用，不含休眠的时钟和包含休眠的时钟之差就是休眠时长；
+// 只有墙上时间跳变时是改了系统时间，计时按 awakeClock 不受影响，只记日志
+func detectSleep() time.Duration {
+	now := time.Now()
+	awake := awakeClock()
+	clock := suspendClock()
+	elapsed := awake - lastTickAwake
+	gap := clock - lastTickClock - elapsed
+	wallJump := now.Round(0).Sub(lastTick.Round(0)) - elapsed
+	lastTick, lastTickAwake, lastTickClock = now, awake, clock
+
+	if gap >= clockJumpThreshold {
+		return gap
+	}
+	if wallJump >= clockJumpThreshold || wallJump <= -clockJumpThreshold {
+		logInfo("system clock changed by %s", wallJump.Round(time.Second))
+	}
+	return 0
+}
+
+// handleSleep 按设置处理休眠，返回计时循环是否继续
+func handleSleep(gap time.Duration) bool {
+	logInfo("system slept for %s, policy %s", gap.Round(time.Second), setting.Data.SleepPolicy)
+	sleepGap += gap
+	switch setting.Data.SleepPolicy {
+	case sleepCount:
+		runningBase += gap
+		return true
+	case sleepAsk:
+		fyne.Do(func() {
+			pauseTimer()
+			showSleepDialog(gap)
+		})
+		return false
+	default:
+		// 计时按 awakeClock 计算，休眠时间本来就不计入，唤醒后接着计时即可
+		return true
+	}
+}
+
+// showSleepDialog 唤醒后询问休眠时间是否计入本次计时，选择后继续计时
+func showSleepDialog(gap time.Duration) {
+	message := tr("sleep.message", formatDuration(gap))
+	dialog.ShowCustomConfirm(tr("sleep.title"), tr("sleep.countButton"), tr("sleep.skipButton"), widget.NewLabel(message), func(count bool) {
+		if currentState != statePause {
+			return
+		}
+		if count {
+			totalRunningTime += gap
+			remaining = total - totalRunningTime
+		}
+		startTimer()
+	}, window)
+}
+
+// createSleepPolicySelect 设置窗口中的休眠处理方式
+func createSleepPolicySelect() fyne.CanvasObject {
+	labels := make([]string, 0, len(sleepPolicyOrder))
+	for _, policy := range sleepPolicyOrder {
+		labels = append(labels, sleepPolicyLabel(policy))
+	}
+	policySelect := widget.NewSelect(labels, func(selected string) {
+		for _, policy := range sleepPolicyOrder {
+			if sleepPolicyLabel(policy) == selected {
+				setting.Data.SleepPolicy = policy
+			}
+		}
+	})
+	policy := setting.Data.SleepPolicy
+	if policy != sleepCount && policy != sleepAsk {
+		policy = sleepPause
+	}
+	policySelect.SetSelected(sleepPolicyLabel(policy))
+	return policySelect
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	appTitle = "XTimer"
+	// 动态图标尺寸，进度环在图标中画得比主界面粗
+	statusIconSize      = 64
+	statusIconThickness = 0.24
+	// statusRingSteps 进度环图标的进度分成多少档，避免每秒都生成新图标
+	statusRingSteps = 48
+	// statusIconInterval 两次更换图标的最短间隔
+	statusIconInterval = 2 * time.Second
+)
+
+var (
+	lastTitle      string
+	lastStatusIcon string
+	lastIconColor  color.NRGBA
+	lastIconUpdate time.Time
+	statusIcons    = make(map[string]fyne.Resource)
+)
+
+// updateWindowTitle 在窗口标题中显示剩余时间和状态，如 "12:34 · 专注中"
+func updateWindowTitle(text string) {
+	title := appTitle
+	if setting.Appearance.TitleTime && currentState != stateIdle {
+		title = tr("title.format", text, titleState())
+	}
+	if title == lastTitle {
+		return
+	}
+	lastTitle = title
+	window.SetTitle(title)
+}
+
+func titleState() string {
+	switch {
+	case overtime:
+		return tr("title.flow")
+	case currentState == stateBreaking:
+		return tr("title.breaking")
+	case currentState == statePause:
+		return tr("title.paused")
+	default:
+		return tr("title.working")
+	}
+}
+
+// statusIconActive 计时中且开启了进度环图标或分钟图标
+func statusIconActive() bool {
+	if currentState == stateIdle {
+		return false
+	}
+	return setting.Appearance.TimeIcon || (setting.Appearance.RingIcon && timerMode() != modeStopwatch)
+}
+
+// updateStatusIcon 用剩余分钟和状态颜色生成窗口和托盘图标，内容不变或间隔太短时不更新
+func updateStatusIcon() {
+	if !statusIconActive() {
+		if lastStatusIcon != "" {
+			lastStatusIcon = ""
+			window.SetIcon(logoImage)
+			updateTray()
+		}
+		return
+	}
+
+	ringStep := -1
+	if setting.Appearance.RingIcon && timerMode() != modeStopwatch {
+		ringStep = int(math.Round(sessionRemaining() * statusRingSteps))
+	}
+	minutes := -1
+	if setting.Appearance.TimeIcon {
+		minutes = iconMinutes()
+	}
+	c := color.NRGBAModel.Convert(timeText.Color).(color.NRGBA)
+	key := fmt.Sprintf("status-%d-%d-%02x%02x%02x.png", ringStep, minutes, c.R, c.G, c.B)
+	if key == lastStatusIcon {
+		return
+	}
+	// 颜色变化（状态切换）立即更新，仅进度或分钟变化时限制频率
+	if lastStatusIcon != "" && c == lastIconColor && time.Since(lastIconUpdate) < statusIconInterval {
+		return
+	}
+	lastStatusIcon = key
+	lastIconColor = c
+	lastIconUpdate = time.Now()
+
+	icon, ok := statusIcons[key]
+	if !ok {
+		data, err := renderStatusIcon(ringStep, minutes, c)
+		if err != nil {
+			logError("render status icon error", err)
+			return
+		}
+		icon = fyne.NewStaticResource(key, data)
+		statusIcons[key] = icon
+	}
+	window.SetIcon(icon)
+	if trayApp != nil {
+		trayApp.SetSystemTrayIcon(icon)
+	}
+}
+
+// iconMinutes 图标上显示的分钟数，倒计时向上取整，正计时显示已用分钟
+func iconMinutes() int {
+	if timerMode() == modeStopwatch {
+		return int(totalRunningTime / time.Minute)
+	}
+	if remaining <= 0 {
+		return 0
+	}
+	return int(math.Ceil(remaining.Minutes()))
+}
+
+// renderStatusIcon 把进度环和分钟数画成 PNG 图标，ringStep 或 minutes 为 -1 时不画对应部分
+func renderStatusIcon(ringStep, minutes int, c color.NRGBA) ([]byte, error) {
+	img := image.NewNRGBA(image.Rect(0, 0, statusIconSize, statusIconSize))
+	textColor := c
+	for y := 0; y < statusIconSize; y++ {
+		for x := 0; x < statusIconSize; x++ {
+			if ringStep >= 0 {
+				value := float64(ringStep) / statusRingSteps
+				img.Set(x, y, ringPixel(x, y, statusIconSize, statusIconSize, value, statusIconThickness, c))
+			} else {
+				// 没有进度环时画实心圆，数字用白色
+				img.Set(x, y, ringPixel(x, y, statusIconSize, statusIconSize, 1, 1, c))
+				textColor = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+		}
+	}
+	if minutes >= 0 {
+		drawIconText(img, iconText(minutes), textColor)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func iconText(minutes int) string {
+	if minutes > 999 {
+		return "999"
+	}
+	return strconv.Itoa(minutes)
+}
+
+// drawIconText 用点阵字体画数字，再按整数倍放大到图标中央
+func drawIconText(dst *image.NRGBA, text string, c color.NRGBA) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil()
+	height := face.Height
+	small := image.NewNRGBA(image.Rect(0, 0, width, height))
+	drawer := &font.Drawer{
+		Dst:  small,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	drawer.DrawString(text)
+
+	scale := 3
+	if len(text) > 2 {
+		scale = 2
+	}
+	w, h := width*scale, height*scale
+	x := (statusIconSize - w) / 2
+	y := (statusIconSize - h) / 2
+	draw.NearestNeighbor.Scale(dst, image.Rect(x, y, x+w, y+h), small, small.Bounds(), draw.Over, nil)
+}
+
+// createStatusSettings 设置窗口中的窗口标题和动态图标开关
+func createStatusSettings() fyne.CanvasObject {
+	titleCheck := widget.NewCheck(tr("status.titleCheck"), func(checked bool) {
+		setting.Appearance.TitleTime = checked
+		updateWindowTitle(displayTime())
+	})
+	titleCheck.SetChecked(setting.Appearance.TitleTime)
+	iconCheck := widget.NewCheck(tr("status.iconCheck"), func(checked bool) {
+		setting.Appearance.TimeIcon = checked
+		updateTray()
+		updateStatusIcon()
+	})
+	iconCheck.SetChecked(setting.Appearance.TimeIcon)
+	return container.NewVBox(titleCheck, iconCheck)
+}
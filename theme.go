@@ -0,0 +1,340 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"image/color"
+	"io"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// themePreset 一套配色，也是主题文件的格式
+type themePreset struct {
+	Name       string `json:"name"`
+	Background string `json:"background"`
+	Work       string `json:"work"`
+	Break      string `json:"break"`
+	Note       string `json:"note"`
+	Stat       string `json:"stat"`
+}
+
+// 内置主题名称，跟随系统时在浅色和深色之间切换
+const (
+	themeLight        = "light"
+	themeDark         = "dark"
+	themeHighContrast = "highContrast"
+)
+
+var builtinThemes = []themePreset{
+	{Name: themeLight, Background: "#FFFFFF", Work: "#DF5D1F", Break: "#7EA56A", Note: "#7EA56A", Stat: "#7EA56A"},
+	{Name: themeDark, Background: "#1E1F22", Work: "#FF8A50", Break: "#8FC27A", Note: "#8FC27A", Stat: "#A9B1BA"},
+	{Name: themeHighContrast, Background: "#000000", Work: "#FFD400", Break: "#00FF66", Note: "#FFFFFF", Stat: "#FFFFFF"},
+}
+
+// systemVariant 最近一次看到的系统深浅色，用来过滤无关的设置变化
+var systemVariant fyne.ThemeVariant
+
+// appTheme 按当前配色调整 Fyne 默认主题，让对话框和设置窗口与主界面一致
+type appTheme struct{}
+
+func (appTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	switch name {
+	case theme.ColorNameBackground, theme.ColorNameMenuBackground, theme.ColorNameOverlayBackground:
+		return bgColor
+	case theme.ColorNamePrimary, theme.ColorNameFocus:
+		return workColor
+	}
+	return theme.DefaultTheme().Color(name, colorVariant(bgColor))
+}
+
+func (appTheme) Font(style fyne.TextStyle) fyne.Resource {
+	return theme.DefaultTheme().Font(style)
+}
+
+func (appTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+	return theme.DefaultTheme().Icon(name)
+}
+
+func (appTheme) Size(name fyne.ThemeSizeName) float32 {
+	return theme.DefaultTheme().Size(name)
+}
+
+// colorVariant 按背景亮度决定控件使用深色还是浅色
+func colorVariant(bg color.Color) fyne.ThemeVariant {
+	r, g, b, _ := bg.RGBA()
+	luminance := 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+	if luminance < 0x8000 {
+		return theme.VariantDark
+	}
+	return theme.VariantLight
+}
+
+func themeLabel(name string) string {
+	for _, preset := range builtinThemes {
+		if preset.Name == name {
+			return tr("theme." + name)
+		}
+	}
+	return name
+}
+
+// allThemes 内置主题和导入的主题
+func allThemes() []themePreset {
+	return append(append([]themePreset(nil), builtinThemes...), setting.Appearance.Themes...)
+}
+
+func findTheme(name string) *themePreset {
+	for _, preset := range allThemes() {
+		if preset.Name == name {
+			found := preset
+			return &found
+		}
+	}
+	return nil
+}
+
+// initTheme 启动时应用配色，并在系统深浅色变化时切换主题
+func initTheme() {
+	systemVariant = myApp.Settings().ThemeVariant()
+	if setting.Appearance.ThemeFollowSystem {
+		applyThemePreset(systemThemeName())
+	}
+	myApp.Settings().SetTheme(appTheme{})
+	myApp.Settings().AddListener(func(s fyne.Settings) {
+		variant := s.ThemeVariant()
+		if variant == systemVariant {
+			return
+		}
+		systemVariant = variant
+		if setting.Appearance.ThemeFollowSystem {
+			applyThemePreset(systemThemeName())
+		}
+		// 设置窗口打开时已应用的设置也跟着切换，取消修改时不会退回原来的主题
+		if preset := findTheme(systemThemeName()); preset != nil &&
+			settingsSnapshot != nil && settingsSnapshot.Appearance.ThemeFollowSystem {
+			setThemeColors(&settingsSnapshot.Appearance, preset)
+		}
+	})
+}
+
+func systemThemeName() string {
+	if systemVariant == theme.VariantDark {
+		return themeDark
+	}
+	return themeLight
+}
+
+// loadColors 把设置中的颜色文本转换为界面颜色，格式错误的保持默认
+func loadColors() {
+	colors := []struct {
+		text   string
+		target *color.Color
+	}{
+		{setting.Appearance.BgColorText, &bgColor},
+		{setting.Appearance.WorkColorText, &workColor},
+		{setting.Appearance.BreakColorText, &breakColor},
+		{setting.Appearance.NoteColorText, &noteColor},
+		{setting.Appearance.StatColorText, &statColor},
+	}
+	for _, c := range colors {
+		if c.text == "" {
+			continue
+		}
+		if toColor, err := hexToColor(c.text); err == nil {
+			*c.target = toColor
+		}
+	}
+}
+
+// applyThemePreset 应用指定主题的配色并刷新界面
+func applyThemePreset(name string) {
+	preset := findTheme(name)
+	if preset == nil {
+		return
+	}
+	setThemeColors(&setting.Appearance, preset)
+	loadColors()
+	refreshColors()
+}
+
+func setThemeColors(a *appearanceSettings, preset *themePreset) {
+	a.Theme = preset.Name
+	a.BgColorText = preset.Background
+	a.WorkColorText = preset.Work
+	a.BreakColorText = preset.Break
+	a.NoteColorText = preset.Note
+	a.StatColorText = preset.Stat
+}
+
+// refreshColors 颜色变化后刷新主界面和 Fyne 主题
+func refreshColors() {
+	if overlay != nil {
+		overlay.FillColor = bgColor
+		overlay.Refresh()
+	}
+	if stateText != nil {
+		stateText.Color = noteColor
+		stateText.Refresh()
+	}
+	if statTimeText != nil {
+		statTimeText.Color = statColor
+		statCountText.Color = statColor
+		statTimeText.Refresh()
+		statCountText.Refresh()
+	}
+	if timeText != nil && !overtime {
+		timeText.Color = sessionColor()
+		timeText.Refresh()
+	}
+	updateBackground()
+	myApp.Settings().SetTheme(appTheme{})
+}
+
+// markCustomTheme 手动修改颜色后不再属于任何主题
+func markCustomTheme() {
+	setting.Appearance.Theme = ""
+	setting.Appearance.ThemeFollowSystem = false
+	refreshColors()
+}
+
+// currentThemePreset 把当前颜色打包成主题，用于导出
+func currentThemePreset(name string) themePreset {
+	return themePreset{
+		Name:       name,
+		Background: colorToHex(bgColor),
+		Work:       colorToHex(workColor),
+		Break:      colorToHex(breakColor),
+		Note:       colorToHex(noteColor),
+		Stat:       colorToHex(statColor),
+	}
+}
+
+func validateTheme(preset themePreset) error {
+	if strings.TrimSpace(preset.Name) == "" {
+		return errors.New(tr("theme.errName"))
+	}
+	for _, text := range []string{preset.Background, preset.Work, preset.Break, preset.Note, preset.Stat} {
+		if _, err := hexToColor(text); err != nil {
+			return errors.New(tr("theme.errColor", text))
+		}
+	}
+	return nil
+}
+
+// importTheme 读取主题文件，同名的导入主题会被覆盖
+func importTheme(reader io.Reader) (*themePreset, error) {
+	var preset themePreset
+	if err := json.NewDecoder(reader).Decode(&preset); err != nil {
+		return nil, err
+	}
+	if err := validateTheme(preset); err != nil {
+		return nil, err
+	}
+	for _, builtin := range builtinThemes {
+		if builtin.Name == preset.Name {
+			return nil, errors.New(tr("theme.errBuiltin", preset.Name))
+		}
+	}
+	for i := range setting.Appearance.Themes {
+		if setting.Appearance.Themes[i].Name == preset.Name {
+			setting.Appearance.Themes[i] = preset
+			return &preset, nil
+		}
+	}
+	setting.Appearance.Themes = append(setting.Appearance.Themes, preset)
+	return &preset, nil
+}
+
+// createThemeSettings 设置窗口中的主题选择、跟随系统和导入导出，
+// onChanged 在颜色变化后调用，用于刷新设置窗口中的颜色项
+func createThemeSettings(onChanged func()) fyne.CanvasObject {
+	var names []string
+	for _, preset := range allThemes() {
+		names = append(names, preset.Name)
+	}
+	labels := make([]string, 0, len(names))
+	for _, name := range names {
+		labels = append(labels, themeLabel(name))
+	}
+
+	themeSelect := widget.NewSelect(labels, nil)
+	themeSelect.PlaceHolder = tr("theme.custom")
+	if setting.Appearance.Theme != "" && findTheme(setting.Appearance.Theme) != nil {
+		themeSelect.Selected = themeLabel(setting.Appearance.Theme)
+	}
+	themeSelect.OnChanged = func(selected string) {
+		for i, label := range labels {
+			if label == selected && names[i] != setting.Appearance.Theme {
+				applyThemePreset(names[i])
+				onChanged()
+			}
+		}
+	}
+
+	followCheck := widget.NewCheck(tr("theme.followSystem"), func(checked bool) {
+		if checked == setting.Appearance.ThemeFollowSystem {
+			return
+		}
+		setting.Appearance.ThemeFollowSystem = checked
+		if checked {
+			applyThemePreset(systemThemeName())
+			onChanged()
+		}
+	})
+	followCheck.SetChecked(setting.Appearance.ThemeFollowSystem)
+
+	importBtn := widget.NewButton(tr("theme.import"), func() {
+		openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+			preset, err := importTheme(reader)
+			if err != nil {
+				logError("import theme error", err)
+				dialog.ShowError(err, settingsWindow)
+				return
+			}
+			setting.Appearance.ThemeFollowSystem = false
+			applyThemePreset(preset.Name)
+			onChanged()
+		}, settingsWindow)
+		openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+		openDialog.Show()
+	})
+
+	exportBtn := widget.NewButton(tr("theme.export"), func() {
+		name := setting.Appearance.Theme
+		if name == "" {
+			name = tr("theme.custom")
+		}
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			defer writer.Close()
+			data, err := json.MarshalIndent(currentThemePreset(name), "", "  ")
+			if err == nil {
+				_, err = writer.Write(data)
+			}
+			if err != nil {
+				logError("export theme error", err)
+				dialog.ShowError(err, settingsWindow)
+			}
+		}, settingsWindow)
+		saveDialog.SetFileName(name + ".json")
+		saveDialog.Show()
+	})
+
+	return container.NewVBox(
+		container.NewHBox(themeSelect, importBtn, exportBtn),
+		followCheck,
+	)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+var (
+	trayApp        desktop.App
+	trayMenu       *fyne.Menu
+	trayTimeItem   *fyne.MenuItem
+	trayToggleItem *fyne.MenuItem
+)
+
+// initTray 在支持系统托盘的桌面平台上创建托盘图标和菜单
+func initTray() {
+	desk, ok := myApp.(desktop.App)
+	if !ok {
+		return
+	}
+	trayApp = desk
+
+	trayTimeItem = fyne.NewMenuItem(displayTime(), nil)
+	trayTimeItem.Disabled = true
+	trayToggleItem = fyne.NewMenuItem(tr("action.start"), func() {
+		toggleTimer()
+	})
+
+	// 标记为退出项，避免托盘再追加一个直接退出的默认菜单
+	quitItem := fyne.NewMenuItem(tr("tray.quit"), quitApp)
+	quitItem.IsQuit = true
+
+	trayMenu = fyne.NewMenu(appTitle,
+		trayTimeItem,
+		fyne.NewMenuItemSeparator(),
+		trayToggleItem,
+		fyne.NewMenuItem(tr("action.reset"), guardStrictBreak(stopTimer)),
+		fyne.NewMenuItem(tr("action.skip"), guardStrictBreak(skipTimer)),
+		fyne.NewMenuItem(tr("action.extend"), extendTimer),
+		fyne.NewMenuItem(tr("action.shorten"), shortenTimer),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(tr("tray.today"), showTodayStats),
+		fyne.NewMenuItem(tr("action.mini"), toggleMiniMode),
+		fyne.NewMenuItem(tr("tray.open"), func() {
+			window.Show()
+			window.RequestFocus()
+		}),
+		fyne.NewMenuItemSeparator(),
+		quitItem,
+	)
+
+	desk.SetSystemTrayMenu(trayMenu)
+	desk.SetSystemTrayIcon(pauseImage)
+}
+
+// updateTray 根据当前状态刷新托盘图标和菜单
+func updateTray() {
+	if trayApp == nil {
+		return
+	}
+
+	switch {
+	case statusIconActive():
+		// 状态变化时颜色可能改变，强制重新生成动态图标
+		lastStatusIcon = ""
+		updateStatusIcon()
+	case currentState == stateWorking:
+		trayApp.SetSystemTrayIcon(workingImage)
+	case currentState == stateBreaking:
+		trayApp.SetSystemTrayIcon(breakingImage)
+	default:
+		trayApp.SetSystemTrayIcon(pauseImage)
+	}
+
+	if isRunning {
+		trayToggleItem.Label = tr("action.pause")
+	} else {
+		trayToggleItem.Label = tr("action.start")
+	}
+	trayTimeItem.Label = trayTimeLabel(displayTime())
+	trayMenu.Refresh()
+}
+
+// updateTrayTime 在每次计时刷新时更新托盘菜单中的剩余时间
+func updateTrayTime(text string) {
+	if trayApp == nil {
+		return
+	}
+	trayTimeItem.Label = trayTimeLabel(text)
+	trayMenu.Refresh()
+}
+
+func trayTimeLabel(text string) string {
+	switch currentState {
+	case stateWorking:
+		return tr("tray.working", text)
+	case stateBreaking:
+		return tr("tray.breaking", text)
+	case statePause:
+		return tr("tray.paused", text)
+	default:
+		return tr("tray.ready", text)
+	}
+}
+
+func showTodayStats() {
+	window.Show()
+	window.RequestFocus()
+	checkAndRefreshToday()
+	message := tr("tray.todayMessage", getPomodoroCount(), getPomodoroTime())
+	stats, err := getTypeStatsByDate(today)
+	if err != nil {
+		logError("query type stats error", err)
+	}
+	for _, stat := range stats {
+		if stat.Type == modePomodoro {
+			continue
+		}
+		label := stat.Type
+		for _, mode := range modeOrder {
+			if mode == stat.Type {
+				label = modeLabel(mode)
+			}
+		}
+		count := trn("tray.typeCount", stat.Count, stat.Count)
+		minutes := trn("tray.typeMinutes", stat.Duration, stat.Duration)
+		message += "\n" + tr("tray.typeStat", label, count, minutes)
+	}
+	dialog.ShowInformation(tr("tray.today"), message, window)
+}
+
+// hideToTray 关闭窗口时隐藏到托盘，返回是否成功隐藏
+func hideToTray() bool {
+	if trayApp == nil || !setting.Integrations.MinimizeToTray {
+		return false
+	}
+	window.Hide()
+	return true
+}
+
+func quitApp() {
+	saveWindowGeometry()
+	saveSettings()
+	if settingsWindow != nil {
+		settingsWindow.Close()
+		settingsWindow = nil
+	}
+	myApp.Quit()
+}
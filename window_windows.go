@@ -0,0 +1,77 @@
+//go:build windows
+
+package main
+
+import (
+	"unsafe"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver"
+	"golang.org/x/sys/windows"
+)
+
+// nativeWindowControl 表示当前平台能否置顶和移动窗口
+const nativeWindowControl = true
+
+const (
+	swpNoSize     = 0x0001
+	swpNoMove     = 0x0002
+	swpNoZOrder   = 0x0004
+	swpNoActivate = 0x0010
+)
+
+var (
+	// HWND_TOPMOST 和 HWND_NOTOPMOST 分别是 (HWND)-1 和 (HWND)-2
+	hwndTopmost   = ^uintptr(0)
+	hwndNoTopmost = ^uintptr(1)
+
+	user32            = windows.NewLazySystemDLL("user32.dll")
+	procSetWindowPos  = user32.NewProc("SetWindowPos")
+	procGetWindowRect = user32.NewProc("GetWindowRect")
+)
+
+func windowHandle(w fyne.Window) (uintptr, bool) {
+	nw, ok := w.(driver.NativeWindow)
+	if !ok {
+		return 0, false
+	}
+	var handle uintptr
+	nw.RunNative(func(ctx any) {
+		if win, ok := ctx.(driver.WindowsWindowContext); ok {
+			handle = win.HWND
+		}
+	})
+	return handle, handle != 0
+}
+
+func setAlwaysOnTop(w fyne.Window, onTop bool) {
+	hwnd, ok := windowHandle(w)
+	if !ok {
+		return
+	}
+	after := hwndNoTopmost
+	if onTop {
+		after = hwndTopmost
+	}
+	procSetWindowPos.Call(hwnd, after, 0, 0, 0, 0, swpNoMove|swpNoSize|swpNoActivate)
+}
+
+func windowPosition(w fyne.Window) (int, int, bool) {
+	hwnd, ok := windowHandle(w)
+	if !ok {
+		return 0, 0, false
+	}
+	var rect windows.Rect
+	if ret, _, _ := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&rect))); ret == 0 {
+		return 0, 0, false
+	}
+	return int(rect.Left), int(rect.Top), true
+}
+
+func moveWindow(w fyne.Window, x, y int) {
+	hwnd, ok := windowHandle(w)
+	if !ok {
+		return
+	}
+	procSetWindowPos.Call(hwnd, 0, uintptr(x), uintptr(y), 0, 0, swpNoSize|swpNoZOrder|swpNoActivate)
+}
@@ -0,0 +1,186 @@
+//go:build linux && !wayland
+
+package main
+
+/*
+#cgo pkg-config: x11 xrandr
+#include <X11/Xlib.h>
+#include <X11/extensions/Xrandr.h>
+
+static Display *xtimerDisplay(void) {
+	static Display *dpy = NULL;
+	if (dpy == NULL) {
+		XInitThreads();
+		dpy = XOpenDisplay(NULL);
+	}
+	return dpy;
+}
+
+static int xtimerMapError;
+
+static int xtimerMapErrorHandler(Display *dpy, XErrorEvent *ev) {
+	xtimerMapError = ev->error_code;
+	return 0;
+}
+
+// xtimerMapState 窗口已显示返回 1，尚未映射返回 0，窗口已销毁等错误返回 -1
+static int xtimerMapState(Window win) {
+	Display *dpy = xtimerDisplay();
+	if (dpy == NULL) {
+		return -1;
+	}
+	XWindowAttributes attrs;
+	xtimerMapError = 0;
+	XErrorHandler previous = XSetErrorHandler(xtimerMapErrorHandler);
+	Status ok = XGetWindowAttributes(dpy, win, &attrs);
+	XSetErrorHandler(previous);
+	if (!ok || xtimerMapError != 0) {
+		return -1;
+	}
+	return attrs.map_state == IsViewable ? 1 : 0;
+}
+
+static void xtimerSetAbove(Window win, int above) {
+	Display *dpy = xtimerDisplay();
+	if (dpy == NULL) {
+		return;
+	}
+	XEvent ev = {0};
+	ev.xclient.type = ClientMessage;
+	ev.xclient.window = win;
+	ev.xclient.message_type = XInternAtom(dpy, "_NET_WM_STATE", False);
+	ev.xclient.format = 32;
+	ev.xclient.data.l[0] = above ? 1 : 0;
+	ev.xclient.data.l[1] = XInternAtom(dpy, "_NET_WM_STATE_ABOVE", False);
+	ev.xclient.data.l[3] = 1;
+	XSendEvent(dpy, DefaultRootWindow(dpy), False,
+		SubstructureRedirectMask | SubstructureNotifyMask, &ev);
+	XFlush(dpy);
+}
+
+static int xtimerPosition(Window win, int *x, int *y) {
+	Display *dpy = xtimerDisplay();
+	if (dpy == NULL) {
+		return 0;
+	}
+	Window child;
+	return XTranslateCoordinates(dpy, win, DefaultRootWindow(dpy), 0, 0, x, y, &child);
+}
+
+static void xtimerMove(Window win, int x, int y) {
+	Display *dpy = xtimerDisplay();
+	if (dpy == NULL) {
+		return;
+	}
+	XMoveWindow(dpy, win, x, y);
+	XFlush(dpy);
+}
+*/
+import "C"
+
+import (
+	"time"
+	"unsafe"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver"
+)
+
+// nativeWindowControl 表示当前平台能否置顶和移动窗口
+const nativeWindowControl = true
+
+const (
+	// 等待窗口映射的检查间隔和最长时间
+	mapCheckInterval = 50 * time.Millisecond
+	mapCheckTimeout  = 2 * time.Second
+)
+
+func x11Window(w fyne.Window) (C.Window, bool) {
+	nw, ok := w.(driver.NativeWindow)
+	if !ok {
+		return 0, false
+	}
+	var handle uintptr
+	nw.RunNative(func(ctx any) {
+		if x11, ok := ctx.(driver.X11WindowContext); ok {
+			handle = x11.WindowHandle
+		}
+	})
+	return C.Window(handle), handle != 0
+}
+
+func setAlwaysOnTop(w fyne.Window, onTop bool) {
+	win, ok := x11Window(w)
+	if !ok {
+		return
+	}
+	above := C.int(0)
+	if onTop {
+		above = 1
+	}
+	// 窗口管理器会忽略发给未映射窗口的 _NET_WM_STATE 消息，
+	// Show() 之后窗口要等窗口管理器处理完才映射，所以等映射后再发送
+	go func() {
+		for waited := time.Duration(0); waited < mapCheckTimeout; waited += mapCheckInterval {
+			switch C.xtimerMapState(win) {
+			case 1:
+				C.xtimerSetAbove(win, above)
+				return
+			case -1:
+				return
+			}
+			time.Sleep(mapCheckInterval)
+		}
+	}()
+}
+
+func windowPosition(w fyne.Window) (int, int, bool) {
+	win, ok := x11Window(w)
+	if !ok {
+		return 0, 0, false
+	}
+	var x, y C.int
+	if C.xtimerPosition(win, &x, &y) == 0 {
+		return 0, 0, false
+	}
+	return int(x), int(y), true
+}
+
+func moveWindow(w fyne.Window, x, y int) {
+	win, ok := x11Window(w)
+	if !ok {
+		return
+	}
+	C.xtimerMove(win, C.int(x), C.int(y))
+}
+
+// listMonitors 通过 RandR 列出当前连接的显示器
+func listMonitors() []monitorInfo {
+	dpy := C.xtimerDisplay()
+	if dpy == nil {
+		return nil
+	}
+	var count C.int
+	monitors := C.XRRGetMonitors(dpy, C.XDefaultRootWindow(dpy), C.True, &count)
+	if monitors == nil {
+		return nil
+	}
+	defer C.XRRFreeMonitors(monitors)
+
+	result := make([]monitorInfo, 0, int(count))
+	for _, m := range unsafe.Slice(monitors, int(count)) {
+		info := monitorInfo{
+			X:       int(m.x),
+			Y:       int(m.y),
+			Width:   int(m.width),
+			Height:  int(m.height),
+			Primary: m.primary != 0,
+		}
+		if name := C.XGetAtomName(dpy, m.name); name != nil {
+			info.Name = C.GoString(name)
+			C.XFree(unsafe.Pointer(name))
+		}
+		result = append(result, info)
+	}
+	return result
+}
@@ -0,0 +1,20 @@
+//go:build (!linux || wayland) && !windows
+
+package main
+
+import "fyne.io/fyne/v2"
+
+// nativeWindowControl 表示当前平台能否置顶和移动窗口
+const nativeWindowControl = false
+
+func setAlwaysOnTop(w fyne.Window, onTop bool) {}
+
+func windowPosition(w fyne.Window) (int, int, bool) {
+	return 0, 0, false
+}
+
+func moveWindow(w fyne.Window, x, y int) {}
+
+func listMonitors() []monitorInfo {
+	return nil
+}